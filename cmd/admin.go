@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/database"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"gorm.io/gorm"
+)
+
+const adminUsage = "usage: admin promote | demote <username>"
+
+// runAdmin handles `admin promote <username>` and `admin demote <username>`. The first admin can
+// only be made this way, since the API lets nobody else grant the role. It returns the process exit
+// code. Each change is audited with "cli" as the actor.
+func runAdmin(cfg *config.Config, args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+	role := models.UserRoleAdmin
+	switch args[0] {
+	case "promote":
+	case "demote":
+		role = models.UserRoleUser
+	default:
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+
+	db, err := database.ConnectDB(cfg)
+	if err != nil {
+		logs.Error("Failed to connect to database", map[string]any{"error": err})
+		return 1
+	}
+	userRepo := repositories.NewUserRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	auditService := services.NewAuditService(&auditRepo)
+
+	ctx := context.Background()
+	user, err := userRepo.GetUserByUsername(ctx, args[1])
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			logs.Error("User not found", map[string]any{"username": args[1]})
+		} else {
+			logs.Error("Failed to look up user", map[string]any{"error": err, "username": args[1]})
+		}
+		return 1
+	}
+	event := models.AuditEvent{
+		Action:     services.AuditActionAdminRole,
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Outcome:    models.AuditOutcomeSuccess,
+		Detail:     "role " + role,
+		UserAgent:  "cli",
+	}
+	if err := userRepo.SetUserRole(ctx, user.ID, role); err != nil {
+		event.Outcome = models.AuditOutcomeFailure
+		auditService.Record(ctx, event)
+		logs.Error("Failed to change user role", map[string]any{"error": err, "username": user.Username})
+		return 1
+	}
+	auditService.Record(ctx, event)
+	logs.Info("User role changed", map[string]any{"username": user.Username, "role": role})
+	return 0
+}
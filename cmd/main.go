@@ -1,73 +1,45 @@
 package main
 
 import (
-	"github.com/asrma7/meroshare-bot/internal/handlers"
-	"github.com/asrma7/meroshare-bot/internal/repositories"
-	"github.com/asrma7/meroshare-bot/internal/routes"
-	"github.com/asrma7/meroshare-bot/internal/services"
+	"fmt"
+	"os"
+
 	"github.com/asrma7/meroshare-bot/pkg/config"
-	"github.com/asrma7/meroshare-bot/pkg/database"
 	"github.com/asrma7/meroshare-bot/pkg/logs"
-	"github.com/asrma7/meroshare-bot/pkg/redis"
-	"github.com/asrma7/meroshare-bot/pkg/utils"
-	"github.com/gin-gonic/gin"
-	"github.com/robfig/cron/v3"
 )
 
+const usage = `usage: meroshare-bot [command]
+
+commands:
+  all       run the API server and the worker in one process (default)
+  server    run the API server
+  worker    run the job worker and the scheduler
+  migrate   manage the database schema (up, down [steps], status)
+  admin     grant or revoke the admin role (promote|demote <username>)`
+
 func main() {
 	logs.InitLogger()
 
 	cfg := config.LoadConfig()
-
-	db, err := database.ConnectDB(cfg)
-	if err != nil {
-		logs.Error("Failed to connect to database", map[string]any{"error": err})
-		return
+	if err := logs.Configure(cfg.Logging); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid logging configuration:", err)
+		os.Exit(2)
 	}
 
-	redisClient := redis.InitRedisClient(cfg)
-	if redisClient == nil {
-		logs.Error("Failed to connect to Redis", nil)
-		return
+	command := "all"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
 	}
 
-	if cfg.Environment == "prod" {
-		gin.SetMode(gin.ReleaseMode)
-	} else {
-		gin.SetMode(gin.DebugMode)
-	}
-
-	r := gin.Default()
-	r.Use(utils.NewCors())
-
-	userRepo := repositories.NewUserRepository(db)
-	accountRepo := repositories.NewAccountRepository(db)
-	shareRepo := repositories.NewShareRepository(db)
-
-	authService := services.NewAuthService(cfg, &userRepo, redisClient)
-	accountService := services.NewAccountService(&accountRepo)
-	shareService := services.NewShareService(&shareRepo)
-	userService := services.NewUserService(db, shareService)
-
-	authHandler := handlers.NewAuthHandler(authService)
-	accountHandler := handlers.NewAccountHandler(accountService)
-	shareHandler := handlers.NewShareHandler(shareService, accountService)
-	userHandler := handlers.NewUserHandler(userService)
-
-	routes.RegisterRoutes(r, authHandler, userHandler, accountHandler, shareHandler)
-
-	c := cron.New()
-	c.AddFunc("0 0 * * *", shareHandler.ApplyShare)
-	c.Start()
-
-	logs.Info("Starting server", map[string]any{
-		"port": cfg.Port,
-		"env":  cfg.Environment,
-	})
-
-	if err := r.Run(":" + cfg.Port); err != nil {
-		logs.Logger.Fatal("Failed to start server", map[string]any{
-			"error": err,
-		})
+	switch command {
+	case "all", "server", "worker":
+		os.Exit(run(cfg, command))
+	case "migrate":
+		os.Exit(runMigrate(cfg, os.Args[2:]))
+	case "admin":
+		os.Exit(runAdmin(cfg, os.Args[2:]))
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
 	}
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/meroshare/mock"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	scenario := flag.String("scenario", string(mock.ScenarioHappy), "happy, invalid_credentials, expired_password, wrong_pin, application_in_process, apply_unavailable, right_unavailable or balance_unavailable")
+	password := flag.String("password", "", "password the mock accepts (empty accepts any)")
+	pin := flag.String("pin", "", "transaction PIN the mock accepts (empty accepts any)")
+	balance := flag.Float64("balance", -1, "bank balance to report (negative reports none)")
+	flag.Parse()
+
+	logs.InitLogger()
+
+	opts := []mock.Option{
+		mock.WithScenario(mock.Scenario(*scenario)),
+		mock.WithCredentials(*password, *pin),
+	}
+	if *balance >= 0 {
+		opts = append(opts, mock.WithBalance(*balance))
+	}
+	server := mock.NewServer(opts...)
+
+	logs.Info("Starting MeroShare mock", map[string]any{
+		"addr":     *addr,
+		"scenario": *scenario,
+		"base_url": "http://localhost" + *addr + mock.BasePath,
+	})
+
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		logs.Logger.Fatal("Failed to start MeroShare mock", map[string]any{
+			"error": err,
+		})
+	}
+}
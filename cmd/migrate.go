@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/database"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate handles `migrate up`, `migrate down [steps]` and `migrate status`. It returns the
+// process exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		logs.Error("Failed to connect to database", map[string]any{"error": err})
+		return 1
+	}
+	migrator, err := database.NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		logs.Error("Failed to load migrations", map[string]any{"error": err})
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			logs.Error("Migration failed", map[string]any{"error": err, "applied": applied})
+			return 1
+		}
+		logs.Info("Migrations applied", map[string]any{"applied": applied})
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		if err != nil {
+			logs.Error("Migration rollback failed", map[string]any{"error": err, "reverted": reverted})
+			return 1
+		}
+		logs.Info("Migrations reverted", map[string]any{"reverted": reverted})
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			logs.Error("Failed to read migration status", map[string]any{"error": err})
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			if status.Version > migrator.LatestVersion() {
+				appliedAt += " (unknown to this binary)"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
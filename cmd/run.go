@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/app"
+	"github.com/asrma7/meroshare-bot/internal/scheduler"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/database"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/redis"
+	"github.com/asrma7/meroshare-bot/pkg/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// run starts the API server, the worker, or both, and returns the process exit code. The API only
+// enqueues jobs; the worker consumes them and runs the scheduler, so the two scale independently.
+// On SIGINT or SIGTERM it stops taking work and gives running requests and jobs up to
+// SHUTDOWN_TIMEOUT to finish.
+func run(cfg *config.Config, command string) int {
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		logs.Error("Failed to set up tracing", map[string]any{"error": err})
+		return 1
+	}
+
+	db, err := database.ConnectDB(cfg)
+	if err != nil {
+		logs.Error("Failed to connect to database", map[string]any{"error": err})
+		return 1
+	}
+
+	redisClient := redis.InitRedisClient(cfg)
+	if redisClient == nil {
+		logs.Error("Failed to connect to Redis", nil)
+		return 1
+	}
+
+	if cfg.Environment == "prod" {
+		gin.SetMode(gin.ReleaseMode)
+	} else {
+		gin.SetMode(gin.DebugMode)
+	}
+
+	application := app.New(cfg, db, redisClient)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var jobScheduler scheduler.Scheduler
+	if command == "all" || command == "worker" {
+		recovered, err := application.ApplyService.RecoverInFlight(ctx)
+		if err != nil {
+			logs.Error("Failed to recover interrupted applications", map[string]any{"error": err})
+		} else if recovered > 0 {
+			logs.Info("Recovered interrupted applications", map[string]any{"count": recovered})
+		}
+
+		jobScheduler = scheduler.NewScheduler(cfg, application.JobService, application.IssueService, application.AccountService, application.HealthService, application.LeaderElector)
+		if err := jobScheduler.Start(); err != nil {
+			logs.Error("Failed to start scheduler", map[string]any{"error": err})
+			return 1
+		}
+		application.Worker.Start()
+	}
+
+	// Every process serves probes and metrics on METRICS_PORT, which is kept off the public network;
+	// the API on PORT answers the probes but not /metrics.
+	servers := []*http.Server{{
+		Addr:              ":" + cfg.MetricsPort,
+		Handler:           application.OpsRouter,
+		ReadHeaderTimeout: 10 * time.Second,
+	}}
+	if command != "worker" {
+		servers = append(servers, &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           application.Router,
+			ReadHeaderTimeout: 10 * time.Second,
+		})
+	}
+
+	serverErr := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			logs.Info("Starting server", map[string]any{
+				"addr": server.Addr,
+				"env":  cfg.Environment,
+			})
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		logs.Info("Shutting down", map[string]any{"timeout": cfg.ShutdownTimeout.String()})
+	case err := <-serverErr:
+		logs.Error("Failed to start server", map[string]any{"error": err})
+		exitCode = 1
+	}
+	// A second signal kills the process instead of waiting for the shutdown.
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logs.Error("Failed to shut down server", map[string]any{"error": err, "addr": server.Addr})
+			exitCode = 1
+		}
+	}
+	if jobScheduler != nil {
+		if err := jobScheduler.Stop(shutdownCtx); err != nil {
+			logs.Error("Timed out waiting for scheduled jobs", map[string]any{"error": err})
+			exitCode = 1
+		}
+		if err := application.Worker.Stop(shutdownCtx); err != nil {
+			logs.Error("Timed out waiting for running jobs", map[string]any{"error": err})
+			exitCode = 1
+		}
+	}
+	// Flushed last so the spans of jobs that finished during the shutdown are exported too.
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logs.Error("Failed to flush traces", map[string]any{"error": err})
+	}
+	return exitCode
+}
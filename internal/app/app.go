@@ -0,0 +1,129 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/asrma7/meroshare-bot/internal/middlewares"
+	"github.com/asrma7/meroshare-bot/internal/policies"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/internal/routes"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/internal/worker"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/metrics"
+	"github.com/asrma7/meroshare-bot/pkg/queue"
+	pkgredis "github.com/asrma7/meroshare-bot/pkg/redis"
+	"github.com/asrma7/meroshare-bot/pkg/tracing"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+// App wires repositories, services and handlers together. The API server, the worker and the
+// integration tests all build on the same App so they exercise identical code paths; each command
+// only starts the parts it runs.
+type App struct {
+	Router         *gin.Engine
+	OpsRouter      *gin.Engine
+	AccountService services.AccountService
+	ApplyService   services.ApplyService
+	IssueService   services.IssueService
+	HealthService  services.HealthService
+	JobService     services.JobService
+	Worker         worker.Worker
+	LeaderElector  pkgredis.LeaderElector
+	Metrics        metrics.Metrics
+}
+
+func New(cfg *config.Config, db *gorm.DB, redisClient *redis.Client) *App {
+	m := metrics.NewMetrics()
+	if sqlDB, err := db.DB(); err == nil {
+		m.RegisterDB(sqlDB)
+	} else {
+		logs.Warn("Failed to export database pool metrics", map[string]any{"error": err})
+	}
+	m.RegisterRedis(redisClient)
+
+	// Spans go to whatever tracer provider tracing.Init installed; without one they are no-ops.
+	// Query variables and Redis arguments are left out since they carry account credentials.
+	if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+		logs.Warn("Failed to trace database queries", map[string]any{"error": err})
+	}
+	if err := redisotel.InstrumentTracing(redisClient, redisotel.WithDBStatement(false)); err != nil {
+		logs.Warn("Failed to trace Redis commands", map[string]any{"error": err})
+	}
+
+	r := gin.Default()
+	r.Use(middlewares.TracingMiddleware(cfg.TracingServiceName))
+	r.Use(middlewares.CorrelationMiddleware())
+	r.Use(utils.NewCors())
+	r.Use(middlewares.MetricsMiddleware(m))
+
+	userRepo := repositories.NewUserRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	shareRepo := repositories.NewShareRepository(db)
+	issueRepo := repositories.NewIssueRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	grantRepo := repositories.NewGrantRepository(db)
+
+	authService := services.NewAuthService(cfg, &userRepo, redisClient)
+	auditService := services.NewAuditService(&auditRepo)
+	grantService := services.NewGrantService(&grantRepo, &userRepo)
+	accountPolicy := policies.NewAccountPolicy(&accountRepo, &grantRepo)
+	// Every call to MeroShare goes through one client so it is logged, measured and traced in one place.
+	cdscTransport := logs.NewCDSCTransport(http.DefaultTransport, cfg.MeroShareBaseURL)
+	recentCDSCCalls := metrics.NewRecentCDSCCalls(redisClient, "meroshare:cdsc:calls")
+	cdscTransport = metrics.NewCDSCTransport(cdscTransport, m, recentCDSCCalls, cfg.MeroShareBaseURL)
+	cdscClient := &http.Client{Transport: tracing.NewCDSCTransport(cdscTransport, cfg.MeroShareBaseURL)}
+
+	accountService := services.NewAccountService(cfg, &accountRepo, cdscClient)
+	adminService := services.NewAdminService(cfg, &userRepo, &accountRepo, recentCDSCCalls, redisClient)
+	shareService := services.NewShareService(cfg, &shareRepo, cdscClient)
+	userService := services.NewUserService(&userRepo, shareService)
+	issueService := services.NewIssueService(&issueRepo)
+	applyService := services.NewApplyService(cfg, accountService, shareService, issueService, accountPolicy, redisClient, m)
+	m.RegisterAccountStatuses(accountService.CountAccountsByStatus)
+	leaderElector := pkgredis.NewLeaderElector(redisClient, "meroshare:scheduler:leader", cfg.InstanceID, cfg.LeaderLeaseTTL)
+
+	jobQueue := queue.NewQueue(redisClient, "meroshare:jobs", cfg.JobRetention)
+	jobService := services.NewJobService(jobQueue)
+	jobWorker := worker.NewWorker(cfg, jobQueue, applyService, accountService)
+
+	authHandler := handlers.NewAuthHandler(authService, auditService)
+	accountHandler := handlers.NewAccountHandler(accountService, jobService, accountPolicy, auditService)
+	shareHandler := handlers.NewShareHandler(shareService, applyService, jobService, accountPolicy, auditService)
+	userHandler := handlers.NewUserHandler(userService, auditService)
+	issueHandler := handlers.NewIssueHandler(issueService, jobService)
+	jobHandler := handlers.NewJobHandler(jobService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	grantHandler := handlers.NewGrantHandler(grantService, accountPolicy, auditService)
+	adminHandler := handlers.NewAdminHandler(adminService, jobService, auditService)
+	healthService := services.NewHealthService(cfg, db, redisClient, leaderElector)
+	healthHandler := handlers.NewHealthHandler(healthService, leaderElector)
+
+	routes.RegisterRoutes(r, authHandler, userHandler, accountHandler, shareHandler, issueHandler, jobHandler, auditHandler, grantHandler, adminHandler, healthHandler)
+
+	// Served on METRICS_PORT by every process, so /metrics stays off the public API.
+	ops := gin.New()
+	ops.Use(gin.Recovery())
+	routes.RegisterHealthRoutes(ops, healthHandler)
+	routes.RegisterMetricsRoutes(ops, m)
+
+	return &App{
+		Router:         r,
+		OpsRouter:      ops,
+		AccountService: accountService,
+		ApplyService:   applyService,
+		IssueService:   issueService,
+		HealthService:  healthService,
+		JobService:     jobService,
+		Worker:         jobWorker,
+		LeaderElector:  leaderElector,
+		Metrics:        m,
+	}
+}
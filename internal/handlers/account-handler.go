@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/policies"
 	"github.com/asrma7/meroshare-bot/internal/requests"
 	"github.com/asrma7/meroshare-bot/internal/responses"
 	"github.com/asrma7/meroshare-bot/internal/services"
@@ -20,19 +21,32 @@ type AccountHandler interface {
 	GetAccountsByUserID(c *gin.Context)
 	UpdateAccount(c *gin.Context)
 	DeleteAccount(c *gin.Context)
+	RestoreAccount(c *gin.Context)
+	VerifyAccount(c *gin.Context)
 }
 
 type accountHandler struct {
 	accountService services.AccountService
+	jobService     services.JobService
+	accountPolicy  policies.AccountPolicy
+	auditService   services.AuditService
 }
 
-func NewAccountHandler(accountService services.AccountService) AccountHandler {
+func NewAccountHandler(accountService services.AccountService, jobService services.JobService, accountPolicy policies.AccountPolicy, auditService services.AuditService) AccountHandler {
 	return &accountHandler{
 		accountService: accountService,
+		jobService:     jobService,
+		accountPolicy:  accountPolicy,
+		auditService:   auditService,
 	}
 }
 
 func (h *accountHandler) CreateAccount(c *gin.Context) {
+	var accountID uuid.UUID
+	defer func() {
+		recordAudit(c, h.auditService, services.AuditActionAccountCreate, "account", userTarget(accountID), uuid.Nil)
+	}()
+
 	userID := c.GetString("userID")
 	if userID == "" {
 		errResp := errors.ErrorResponse{
@@ -57,7 +71,7 @@ func (h *accountHandler) CreateAccount(c *gin.Context) {
 		return
 	}
 
-	authorization, err := h.accountService.LoginAccount(req.ClientId, req.Username, req.Password)
+	authorization, err := h.accountService.LoginAccount(c.Request.Context(), req.ClientId, req.Username, req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,13 +85,13 @@ func (h *accountHandler) CreateAccount(c *gin.Context) {
 
 	g.Go(func() error {
 		var err error
-		userDetails, err = h.accountService.FetchUserDetails(authorization)
+		userDetails, err = h.accountService.FetchUserDetails(c.Request.Context(), authorization)
 		return err
 	})
 
 	g.Go(func() error {
 		var err error
-		bankDetails, err = h.accountService.FetchBankDetails(authorization, fmt.Sprintf("%v", req.BankId))
+		bankDetails, err = h.accountService.FetchBankDetails(c.Request.Context(), authorization, fmt.Sprintf("%v", req.BankId))
 		return err
 	})
 
@@ -87,35 +101,45 @@ func (h *accountHandler) CreateAccount(c *gin.Context) {
 	}
 
 	account := models.Account{
-		UserID:             userIDParsed,
-		Name:               userDetails.Name,
-		Email:              userDetails.Email,
-		Contact:            userDetails.Contact,
-		ClientID:           req.ClientId,
-		Username:           req.Username,
-		Password:           req.Password,
-		BankID:             req.BankId,
-		CRNNumber:          req.CRNNumber,
-		TransactionPIN:     req.TransactionPIN,
-		AccountTypeId:      bankDetails[0].AccountTypeId,
-		PreferredKitta:     fmt.Sprintf("%d", req.PreferredKitta),
-		Demat:              userDetails.Demat,
-		BOID:               userDetails.BOID,
-		AccountNumber:      bankDetails[0].AccountNumber,
-		CustomerId:         bankDetails[0].ID,
-		AccountBranchId:    bankDetails[0].AccountBranchId,
-		DMATExpiryDate:     userDetails.DematExpiryDate,
-		PasswordExpiryDate: userDetails.PasswordExpiryDate,
-		ExpiredDate:        userDetails.ExpiredDate,
-	}
-
-	account_id, err := h.accountService.CreateAccount(&account)
+		UserID:              userIDParsed,
+		Name:                userDetails.Name,
+		Email:               userDetails.Email,
+		Contact:             userDetails.Contact,
+		ClientID:            req.ClientId,
+		Username:            req.Username,
+		Password:            req.Password,
+		BankID:              req.BankId,
+		CRNNumber:           req.CRNNumber,
+		TransactionPIN:      req.TransactionPIN,
+		AccountTypeId:       bankDetails[0].AccountTypeId,
+		PreferredKitta:      fmt.Sprintf("%d", req.PreferredKitta),
+		RightShareFraction:  req.RightShareFraction,
+		ApplyMutualFunds:    req.ApplyMutualFunds,
+		MutualFundUnits:     req.MutualFundUnits,
+		MutualFundMaxAmount: req.MutualFundMaxAmount,
+		ApplyDebentures:     req.ApplyDebentures,
+		DebentureUnits:      req.DebentureUnits,
+		DebentureMaxAmount:  req.DebentureMaxAmount,
+		District:            req.District,
+		ForeignEmployment:   req.ForeignEmployment,
+		AvailableBalance:    req.AvailableBalance,
+		Demat:               userDetails.Demat,
+		BOID:                userDetails.BOID,
+		AccountNumber:       bankDetails[0].AccountNumber,
+		CustomerId:          bankDetails[0].ID,
+		AccountBranchId:     bankDetails[0].AccountBranchId,
+		DMATExpiryDate:      userDetails.DematExpiryDate,
+		PasswordExpiryDate:  userDetails.PasswordExpiryDate,
+		ExpiredDate:         userDetails.ExpiredDate,
+	}
+
+	accountID, err = h.accountService.CreateAccount(c.Request.Context(), &account)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Account created successfully", "account_id": account_id})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Account created successfully", "account_id": accountID})
 }
 
 func (h *accountHandler) GetAccountByID(c *gin.Context) {
@@ -143,18 +167,15 @@ func (h *accountHandler) GetAccountByID(c *gin.Context) {
 		return
 	}
 
-	account, err := h.accountService.GetAccountByID(parsedAccountID)
+	account, role, err := h.accountPolicy.Authorize(c.Request.Context(), userIDParsed, parsedAccountID, policies.ActionView)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	if account.UserID != userIDParsed {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
 		return
 	}
+	redactCredentials(account, role)
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "account": account})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "account": account, "role": role})
 }
 
 func (h *accountHandler) GetAccountsByUserID(c *gin.Context) {
@@ -170,16 +191,22 @@ func (h *accountHandler) GetAccountsByUserID(c *gin.Context) {
 		return
 	}
 
-	accounts, err := h.accountService.GetAccountsByUserID(userIDParsed)
+	accounts, roles, err := h.accountPolicy.Accounts(c.Request.Context(), userIDParsed, policies.ActionView)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
 		return
 	}
+	for i := range accounts {
+		redactCredentials(&accounts[i], roles[accounts[i].ID])
+	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "accounts": accounts})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "accounts": accounts, "roles": roles})
 }
 
 func (h *accountHandler) UpdateAccount(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAccountUpdate, "account", c.Param("id"), uuid.Nil)
+
 	userID := c.GetString("userID")
 	if userID == "" {
 		errResp := errors.ErrorResponse{
@@ -211,13 +238,10 @@ func (h *accountHandler) UpdateAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := h.accountService.GetAccountByID(parsedAccountId)
+	account, _, err := h.accountPolicy.Authorize(c.Request.Context(), userIDParsed, parsedAccountId, policies.ActionUpdate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	if account.UserID != userIDParsed {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
 		return
 	}
 
@@ -227,7 +251,7 @@ func (h *accountHandler) UpdateAccount(c *gin.Context) {
 		return
 	}
 
-	authorization, err := h.accountService.LoginAccount(req.ClientId, req.Username, req.Password)
+	authorization, err := h.accountService.LoginAccount(c.Request.Context(), req.ClientId, req.Username, req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -241,13 +265,13 @@ func (h *accountHandler) UpdateAccount(c *gin.Context) {
 
 	g.Go(func() error {
 		var err error
-		userDetails, err = h.accountService.FetchUserDetails(authorization)
+		userDetails, err = h.accountService.FetchUserDetails(c.Request.Context(), authorization)
 		return err
 	})
 
 	g.Go(func() error {
 		var err error
-		bankDetails, err = h.accountService.FetchBankDetails(authorization, fmt.Sprintf("%v", req.BankId))
+		bankDetails, err = h.accountService.FetchBankDetails(c.Request.Context(), authorization, fmt.Sprintf("%v", req.BankId))
 		return err
 	})
 
@@ -257,31 +281,41 @@ func (h *accountHandler) UpdateAccount(c *gin.Context) {
 	}
 
 	updatedAccount := models.Account{
-		ID:                 parsedAccountId,
-		UserID:             userIDParsed,
-		Name:               userDetails.Name,
-		Email:              userDetails.Email,
-		Contact:            userDetails.Contact,
-		ClientID:           req.ClientId,
-		Username:           req.Username,
-		Password:           req.Password,
-		BankID:             req.BankId,
-		CRNNumber:          req.CRNNumber,
-		TransactionPIN:     req.TransactionPIN,
-		AccountTypeId:      bankDetails[0].AccountTypeId,
-		PreferredKitta:     fmt.Sprintf("%d", req.PreferredKitta),
-		Demat:              userDetails.Demat,
-		BOID:               userDetails.BOID,
-		AccountNumber:      bankDetails[0].AccountNumber,
-		CustomerId:         bankDetails[0].ID,
-		AccountBranchId:    bankDetails[0].AccountBranchId,
-		DMATExpiryDate:     userDetails.DematExpiryDate,
-		PasswordExpiryDate: userDetails.PasswordExpiryDate,
-		ExpiredDate:        userDetails.ExpiredDate,
-		Status:             "active",
-	}
-
-	err = h.accountService.UpdateAccount(&updatedAccount)
+		ID:                  parsedAccountId,
+		UserID:              account.UserID,
+		Name:                userDetails.Name,
+		Email:               userDetails.Email,
+		Contact:             userDetails.Contact,
+		ClientID:            req.ClientId,
+		Username:            req.Username,
+		Password:            req.Password,
+		BankID:              req.BankId,
+		CRNNumber:           req.CRNNumber,
+		TransactionPIN:      req.TransactionPIN,
+		AccountTypeId:       bankDetails[0].AccountTypeId,
+		PreferredKitta:      fmt.Sprintf("%d", req.PreferredKitta),
+		RightShareFraction:  req.RightShareFraction,
+		ApplyMutualFunds:    req.ApplyMutualFunds,
+		MutualFundUnits:     req.MutualFundUnits,
+		MutualFundMaxAmount: req.MutualFundMaxAmount,
+		ApplyDebentures:     req.ApplyDebentures,
+		DebentureUnits:      req.DebentureUnits,
+		DebentureMaxAmount:  req.DebentureMaxAmount,
+		District:            req.District,
+		ForeignEmployment:   req.ForeignEmployment,
+		AvailableBalance:    req.AvailableBalance,
+		Demat:               userDetails.Demat,
+		BOID:                userDetails.BOID,
+		AccountNumber:       bankDetails[0].AccountNumber,
+		CustomerId:          bankDetails[0].ID,
+		AccountBranchId:     bankDetails[0].AccountBranchId,
+		DMATExpiryDate:      userDetails.DematExpiryDate,
+		PasswordExpiryDate:  userDetails.PasswordExpiryDate,
+		ExpiredDate:         userDetails.ExpiredDate,
+		Status:              "active",
+	}
+
+	err = h.accountService.UpdateAccount(c.Request.Context(), &updatedAccount)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -291,6 +325,8 @@ func (h *accountHandler) UpdateAccount(c *gin.Context) {
 }
 
 func (h *accountHandler) DeleteAccount(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAccountDelete, "account", c.Param("id"), uuid.Nil)
+
 	userID := c.GetString("userID")
 	if userID == "" {
 		errResp := errors.ErrorResponse{
@@ -321,22 +357,121 @@ func (h *accountHandler) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := h.accountService.GetAccountByID(parsedAccountId)
+	if _, _, err := h.accountPolicy.Authorize(c.Request.Context(), userIDParsed, parsedAccountId, policies.ActionDelete); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	err = h.accountService.DeleteAccount(c.Request.Context(), parsedAccountId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if account.UserID != userIDParsed {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to delete this account"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Account deleted successfully"})
+}
+
+func (h *accountHandler) RestoreAccount(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAccountRestore, "account", c.Param("id"), uuid.Nil)
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return
+	}
+	userIDParsed, err := uuid.Parse(userID)
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid user ID format",
+		}
+		c.JSON(http.StatusBadRequest, errResp)
 		return
 	}
 
-	err = h.accountService.DeleteAccount(parsedAccountId)
+	parsedAccountId, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Account ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Account deleted successfully"})
+	account, err := h.accountService.GetDeletedAccountByID(c.Request.Context(), parsedAccountId)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+	if _, err := h.accountPolicy.Check(c.Request.Context(), userIDParsed, account, policies.ActionDelete); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	if err := h.accountService.RestoreAccount(c.Request.Context(), account); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Account restored successfully", "account": account})
+}
+
+func (h *accountHandler) VerifyAccount(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAccountVerify, "account", c.Param("id"), uuid.Nil)
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return
+	}
+	userIDParsed, err := uuid.Parse(userID)
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid user ID format",
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+
+	parsedAccountId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Account ID"})
+		return
+	}
+
+	account, _, err := h.accountPolicy.Authorize(c.Request.Context(), userIDParsed, parsedAccountId, policies.ActionUpdate)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	job, err := h.jobService.Enqueue(c.Request.Context(), services.JobTypeVerify, userID, services.VerifyJobPayload{AccountID: account.ID.String()})
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "job": job})
+}
+
+// redactCredentials clears the MeroShare credentials from an account the user may only view.
+func redactCredentials(account *models.Account, role string) {
+	if policies.Allows(role, policies.ActionViewCredentials) {
+		return
+	}
+	account.Password = ""
+	account.CRNNumber = ""
+	account.TransactionPIN = ""
 }
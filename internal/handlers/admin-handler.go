@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const defaultCDSCErrorsWindow = time.Hour
+
+type AdminHandler interface {
+	Authorize(c *gin.Context) bool
+	ListUsers(c *gin.Context)
+	GetAccountStats(c *gin.Context)
+	TriggerRun(c *gin.Context)
+	GetJob(c *gin.Context)
+	DisableUser(c *gin.Context)
+	EnableUser(c *gin.Context)
+	GetCDSCErrors(c *gin.Context)
+}
+
+type adminHandler struct {
+	adminService services.AdminService
+	jobService   services.JobService
+	auditService services.AuditService
+}
+
+func NewAdminHandler(adminService services.AdminService, jobService services.JobService, auditService services.AuditService) AdminHandler {
+	return &adminHandler{
+		adminService: adminService,
+		jobService:   jobService,
+		auditService: auditService,
+	}
+}
+
+// Authorize responds with 403 and returns false unless the authenticated user is an admin. Refused
+// attempts are audited against the route that was asked for.
+func (h *adminHandler) Authorize(c *gin.Context) bool {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return false
+	}
+
+	isAdmin, err := h.adminService.IsAdmin(c.Request.Context(), userID)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return false
+	}
+	if !isAdmin {
+		errorResp, statusCode := errors.GetErrorResponse(errors.NewForbiddenError("Admin access required"))
+		c.JSON(statusCode, errorResp)
+		recordAudit(c, h.auditService, services.AuditActionAdminAccess, "route", c.FullPath(), uuid.Nil)
+		return false
+	}
+	return true
+}
+
+func (h *adminHandler) ListUsers(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAdminUsers, "", "", uuid.Nil)
+
+	users, err := h.adminService.ListUsers(c.Request.Context())
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "users": users})
+}
+
+func (h *adminHandler) GetAccountStats(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAdminStats, "", "", uuid.Nil)
+
+	counts, err := h.adminService.CountAccountsByStatus(c.Request.Context())
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "accounts_by_status": counts})
+}
+
+// TriggerRun queues an apply run over every account, or an issue calendar sync, for the worker.
+func (h *adminHandler) TriggerRun(c *gin.Context) {
+	var jobID string
+	defer func() { recordAudit(c, h.auditService, services.AuditActionAdminRun, "job", jobID, uuid.Nil) }()
+
+	var req requests.AdminRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid request body",
+			Details: map[string]string{"error": err.Error()},
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+
+	job, err := h.jobService.Enqueue(c.Request.Context(), req.Type, "", nil)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+	jobID = job.ID
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "job": job})
+}
+
+// GetJob reports on any job, including the runs TriggerRun queues, which belong to no user and so
+// cannot be read through /jobs/:id.
+func (h *adminHandler) GetJob(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAdminJob, "job", c.Param("id"), uuid.Nil)
+
+	job, err := h.jobService.GetAnyJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "job": job})
+}
+
+func (h *adminHandler) DisableUser(c *gin.Context) {
+	h.setUserDisabled(c, services.AuditActionAdminDisable, true)
+}
+
+func (h *adminHandler) EnableUser(c *gin.Context) {
+	h.setUserDisabled(c, services.AuditActionAdminEnable, false)
+}
+
+func (h *adminHandler) setUserDisabled(c *gin.Context, action string, disabled bool) {
+	defer recordAudit(c, h.auditService, action, "user", c.Param("id"), uuid.Nil)
+
+	adminID, _ := uuid.Parse(c.GetString("userID"))
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "BAD_REQUEST",
+			Message: "Invalid user ID",
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+
+	user, err := h.adminService.SetUserDisabled(c.Request.Context(), adminID, userID, disabled)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "user": user})
+}
+
+// GetCDSCErrors reports MeroShare call counts and error rates per endpoint over the last window,
+// given as a duration such as 15m or 6h.
+func (h *adminHandler) GetCDSCErrors(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionAdminCDSC, "", "", uuid.Nil)
+
+	var filter requests.CDSCErrorsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid query parameters",
+			Details: map[string]string{"error": err.Error()},
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+	window := defaultCDSCErrorsWindow
+	if filter.Window != "" {
+		parsed, err := time.ParseDuration(filter.Window)
+		if err != nil || parsed < time.Minute || parsed > metrics.MaxRecentWindow {
+			errResp := errors.ErrorResponse{
+				Type:    "VALIDATION_ERROR",
+				Message: "Invalid query parameters",
+				Details: map[string]string{"window": "must be a duration between 1m and 24h"},
+			}
+			c.JSON(http.StatusBadRequest, errResp)
+			return
+		}
+		window = parsed
+	}
+
+	stats, err := h.adminService.RecentCDSCCalls(c.Request.Context(), window)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "window": window.String(), "endpoints": stats})
+}
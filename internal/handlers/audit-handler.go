@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditHandler interface {
+	GetAuditEvents(c *gin.Context)
+}
+
+type auditHandler struct {
+	auditService services.AuditService
+}
+
+func NewAuditHandler(auditService services.AuditService) AuditHandler {
+	return &auditHandler{
+		auditService: auditService,
+	}
+}
+
+func (h *auditHandler) GetAuditEvents(c *gin.Context) {
+	userID := c.GetString("userID")
+	userIDParsed, err := uuid.Parse(userID)
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return
+	}
+
+	var filter requests.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid query parameters",
+			Details: map[string]string{"error": err.Error()},
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+
+	events, err := h.auditService.GetAuditEventsByUserID(c.Request.Context(), userIDParsed, filter)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "events": events})
+}
+
+// recordAudit writes an audit event for the request once its response is written, so it is
+// deferred at the top of a handler. The outcome follows from the response status. The actor is
+// the authenticated user, or actor for requests made before authenticating, such as a login;
+// uuid.Nil leaves it unknown.
+func recordAudit(c *gin.Context, auditService services.AuditService, action, targetType, targetID string, actor uuid.UUID) {
+	event := models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Outcome:    models.AuditOutcomeSuccess,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	}
+	if userID, err := uuid.Parse(c.GetString("userID")); err == nil {
+		actor = userID
+	}
+	if actor != uuid.Nil {
+		event.UserID = &actor
+	}
+	switch status := c.Writer.Status(); {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		event.Outcome = models.AuditOutcomeDenied
+		event.Detail = http.StatusText(status)
+	case status >= http.StatusBadRequest:
+		event.Outcome = models.AuditOutcomeFailure
+		event.Detail = http.StatusText(status)
+	}
+	auditService.Record(c.Request.Context(), event)
+}
+
+func userTarget(userID uuid.UUID) string {
+	if userID == uuid.Nil {
+		return ""
+	}
+	return userID.String()
+}
@@ -20,16 +20,23 @@ type AuthHandler interface {
 }
 
 type authHandler struct {
-	authService services.AuthService
+	authService  services.AuthService
+	auditService services.AuditService
 }
 
-func NewAuthHandler(authService services.AuthService) AuthHandler {
+func NewAuthHandler(authService services.AuthService, auditService services.AuditService) AuthHandler {
 	return &authHandler{
-		authService: authService,
+		authService:  authService,
+		auditService: auditService,
 	}
 }
 
 func (h *authHandler) Register(c *gin.Context) {
+	var userID uuid.UUID
+	defer func() {
+		recordAudit(c, h.auditService, services.AuditActionRegister, "user", userTarget(userID), userID)
+	}()
+
 	var req requests.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errResp := errors.ErrorResponse{
@@ -41,7 +48,7 @@ func (h *authHandler) Register(c *gin.Context) {
 		return
 	}
 
-	userID, err := h.authService.RegisterUser(req.Username, req.Password, req.Email, utils.CapitalizeFirstLetter(req.FirstName), utils.CapitalizeFirstLetter(req.LastName))
+	userID, err := h.authService.RegisterUser(c.Request.Context(), req.Username, req.Password, req.Email, utils.CapitalizeFirstLetter(req.FirstName), utils.CapitalizeFirstLetter(req.LastName))
 	if err != nil {
 		if appErr, ok := errors.IsAppError(err); ok {
 			errorResp := errors.ErrorResponse{
@@ -66,6 +73,9 @@ func (h *authHandler) Register(c *gin.Context) {
 }
 
 func (h *authHandler) Login(c *gin.Context) {
+	var userID uuid.UUID
+	defer func() { recordAudit(c, h.auditService, services.AuditActionLogin, "user", userTarget(userID), userID) }()
+
 	var req requests.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errResp := errors.ErrorResponse{
@@ -77,7 +87,7 @@ func (h *authHandler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.LoginUser(req.Identifier, req.Password)
+	userID, accessToken, refreshToken, err := h.authService.LoginUser(c.Request.Context(), req.Identifier, req.Password)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
@@ -92,6 +102,11 @@ func (h *authHandler) Login(c *gin.Context) {
 }
 
 func (h *authHandler) RefreshToken(c *gin.Context) {
+	var userID uuid.UUID
+	defer func() {
+		recordAudit(c, h.auditService, services.AuditActionRefreshToken, "user", userTarget(userID), userID)
+	}()
+
 	var req requests.RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errResp := errors.ErrorResponse{
@@ -103,7 +118,7 @@ func (h *authHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.RefreshToken(req.RefreshToken)
+	userID, accessToken, refreshToken, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
@@ -137,7 +152,7 @@ func (h *authHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetProfile(userIDParsed)
+	user, err := h.authService.GetProfile(c.Request.Context(), userIDParsed)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
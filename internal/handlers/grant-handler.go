@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/policies"
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GrantHandler interface {
+	InviteUser(c *gin.Context)
+	GetAccountGrants(c *gin.Context)
+	RevokeGrant(c *gin.Context)
+	GetUserGrants(c *gin.Context)
+	AcceptGrant(c *gin.Context)
+	LeaveGrant(c *gin.Context)
+}
+
+type grantHandler struct {
+	grantService  services.GrantService
+	accountPolicy policies.AccountPolicy
+	auditService  services.AuditService
+}
+
+func NewGrantHandler(grantService services.GrantService, accountPolicy policies.AccountPolicy, auditService services.AuditService) GrantHandler {
+	return &grantHandler{
+		grantService:  grantService,
+		accountPolicy: accountPolicy,
+		auditService:  auditService,
+	}
+}
+
+func (h *grantHandler) InviteUser(c *gin.Context) {
+	var grantID uuid.UUID
+	defer func() {
+		recordAudit(c, h.auditService, services.AuditActionGrantInvite, "grant", userTarget(grantID), uuid.Nil)
+	}()
+
+	userID, accountID, ok := userAndAccountID(c)
+	if !ok {
+		return
+	}
+	account, _, err := h.accountPolicy.Authorize(c.Request.Context(), userID, accountID, policies.ActionManageGrants)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	var req requests.GrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid request body",
+			Details: map[string]string{"error": err.Error()},
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+
+	grant, err := h.grantService.InviteUser(c.Request.Context(), account, userID, req.Identifier, req.Role)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+	grantID = grant.ID
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "grant": grant})
+}
+
+func (h *grantHandler) GetAccountGrants(c *gin.Context) {
+	userID, accountID, ok := userAndAccountID(c)
+	if !ok {
+		return
+	}
+	if _, _, err := h.accountPolicy.Authorize(c.Request.Context(), userID, accountID, policies.ActionManageGrants); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	grants, err := h.grantService.GetGrantsByAccountID(c.Request.Context(), accountID)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "grants": grants})
+}
+
+func (h *grantHandler) RevokeGrant(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionGrantRemove, "grant", c.Param("grantID"), uuid.Nil)
+
+	userID, accountID, ok := userAndAccountID(c)
+	if !ok {
+		return
+	}
+	grantID, err := uuid.Parse(c.Param("grantID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Grant ID"})
+		return
+	}
+	if _, _, err := h.accountPolicy.Authorize(c.Request.Context(), userID, accountID, policies.ActionManageGrants); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	grant, err := h.grantService.GetGrantByID(c.Request.Context(), grantID)
+	if err == nil && grant.AccountID != accountID {
+		err = errors.NewNotFoundError("Grant not found")
+	}
+	if err == nil {
+		err = h.grantService.DeleteGrant(c.Request.Context(), grantID)
+	}
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Access revoked"})
+}
+
+// GetUserGrants lists the invitations the user has received and the access they have accepted.
+func (h *grantHandler) GetUserGrants(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return
+	}
+
+	grants, err := h.grantService.GetGrantsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "grants": grants})
+}
+
+func (h *grantHandler) AcceptGrant(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionGrantAccept, "grant", c.Param("id"), uuid.Nil)
+
+	grant, ok := h.ownGrant(c)
+	if !ok {
+		return
+	}
+	if err := h.grantService.AcceptGrant(c.Request.Context(), grant); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "grant": grant})
+}
+
+// LeaveGrant declines an invitation, or gives up access that was accepted earlier.
+func (h *grantHandler) LeaveGrant(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionGrantRemove, "grant", c.Param("id"), uuid.Nil)
+
+	grant, ok := h.ownGrant(c)
+	if !ok {
+		return
+	}
+	if err := h.grantService.DeleteGrant(c.Request.Context(), grant.ID); err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "message": "Access removed"})
+}
+
+// ownGrant loads the grant named in the path, which must have been given to the current user.
+// Other users' grants are reported as not found.
+func (h *grantHandler) ownGrant(c *gin.Context) (*models.AccountGrant, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return nil, false
+	}
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Grant ID"})
+		return nil, false
+	}
+
+	grant, err := h.grantService.GetGrantByID(c.Request.Context(), grantID)
+	if err == nil && grant.UserID != userID {
+		err = errors.NewNotFoundError("Grant not found")
+	}
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return nil, false
+	}
+	return grant, true
+}
+
+// userAndAccountID reads the current user and the account in the path, responding with an error
+// when either is missing or malformed.
+func userAndAccountID(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return uuid.Nil, uuid.Nil, false
+	}
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Account ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, accountID, true
+}
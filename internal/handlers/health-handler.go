@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/redis"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler interface {
+	Health(c *gin.Context)
+	Ready(c *gin.Context)
+}
+
+type healthHandler struct {
+	healthService services.HealthService
+	leaderElector redis.LeaderElector
+}
+
+func NewHealthHandler(healthService services.HealthService, leaderElector redis.LeaderElector) HealthHandler {
+	return &healthHandler{
+		healthService: healthService,
+		leaderElector: leaderElector,
+	}
+}
+
+// Health is the liveness probe: it checks no dependencies, so an outage elsewhere does not get the
+// process restarted. It reports leadership as this process last saw it; /readyz asks Redis.
+func (h *healthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "success", "leader": h.leaderElector.LocalStatus()})
+}
+
+// Ready is the readiness probe. It answers 503 while any critical dependency is down.
+func (h *healthHandler) Ready(c *gin.Context) {
+	report := h.healthService.Ready(c.Request.Context())
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "error", "readiness": report})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "readiness": report})
+}
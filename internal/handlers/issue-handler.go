@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type IssueHandler interface {
+	GetIssues(c *gin.Context)
+	SyncIssues(c *gin.Context)
+}
+
+type issueHandler struct {
+	issueService services.IssueService
+	jobService   services.JobService
+}
+
+func NewIssueHandler(issueService services.IssueService, jobService services.JobService) IssueHandler {
+	return &issueHandler{
+		issueService: issueService,
+		jobService:   jobService,
+	}
+}
+
+func (h *issueHandler) GetIssues(c *gin.Context) {
+	var filter requests.IssueFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid query parameters",
+			Details: map[string]string{"error": err.Error()},
+		}
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+
+	issues, err := h.issueService.GetIssues(c.Request.Context(), filter)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "issues": issues})
+}
+
+func (h *issueHandler) SyncIssues(c *gin.Context) {
+	job, err := h.jobService.Enqueue(c.Request.Context(), services.JobTypeSync, c.GetString("userID"), nil)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "success", "job": job})
+}
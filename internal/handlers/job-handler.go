@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler interface {
+	GetJob(c *gin.Context)
+}
+
+type jobHandler struct {
+	jobService services.JobService
+}
+
+func NewJobHandler(jobService services.JobService) JobHandler {
+	return &jobHandler{
+		jobService: jobService,
+	}
+}
+
+func (h *jobHandler) GetJob(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return
+	}
+
+	job, err := h.jobService.GetJob(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "job": job})
+}
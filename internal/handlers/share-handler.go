@@ -1,17 +1,15 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/policies"
+	"github.com/asrma7/meroshare-bot/internal/requests"
 	"github.com/asrma7/meroshare-bot/internal/services"
 	"github.com/asrma7/meroshare-bot/pkg/errors"
-	"github.com/asrma7/meroshare-bot/pkg/logs"
-	"github.com/asrma7/meroshare-bot/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ShareHandler interface {
@@ -19,18 +17,24 @@ type ShareHandler interface {
 	GetAppliedShareErrors(c *gin.Context)
 	GetAppliedShareByID(c *gin.Context)
 	MarkShareErrorsAsSeenByUserID(c *gin.Context)
-	ApplyShare()
+	RunApply(c *gin.Context)
 }
 
 type shareHandler struct {
-	shareService   services.ShareService
-	accountService services.AccountService
+	shareService  services.ShareService
+	applyService  services.ApplyService
+	jobService    services.JobService
+	accountPolicy policies.AccountPolicy
+	auditService  services.AuditService
 }
 
-func NewShareHandler(shareService services.ShareService, accountService services.AccountService) ShareHandler {
+func NewShareHandler(shareService services.ShareService, applyService services.ApplyService, jobService services.JobService, accountPolicy policies.AccountPolicy, auditService services.AuditService) ShareHandler {
 	return &shareHandler{
-		shareService:   shareService,
-		accountService: accountService,
+		shareService:  shareService,
+		applyService:  applyService,
+		jobService:    jobService,
+		accountPolicy: accountPolicy,
+		auditService:  auditService,
 	}
 }
 
@@ -45,7 +49,12 @@ func (h *shareHandler) GetAppliedShares(c *gin.Context) {
 		return
 	}
 
-	appliedShares, err := h.shareService.GetAppliedSharesByUserID(userID)
+	accountIDs, ok := h.accountIDs(c, userID, policies.ActionView)
+	if !ok {
+		return
+	}
+
+	appliedShares, err := h.shareService.GetAppliedSharesByUserID(c.Request.Context(), userID, accountIDs)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
@@ -66,7 +75,12 @@ func (h *shareHandler) GetAppliedShareErrors(c *gin.Context) {
 		return
 	}
 
-	appliedShareErrors, err := h.shareService.GetAppliedShareErrorsByUserID(userID)
+	accountIDs, ok := h.accountIDs(c, userID, policies.ActionView)
+	if !ok {
+		return
+	}
+
+	appliedShareErrors, err := h.shareService.GetAppliedShareErrorsByUserID(c.Request.Context(), userID, accountIDs)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
@@ -87,17 +101,33 @@ func (h *shareHandler) GetAppliedShareByID(c *gin.Context) {
 		return
 	}
 
-	appliedShare, appliedShareError, err := h.shareService.GetAppliedShareByID(id)
+	appliedShare, attempts, err := h.shareService.GetAppliedShareByID(c.Request.Context(), id)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
 		return
 	}
+	// Shares applied for on the user's own accounts stay visible after the account is deleted.
+	userID, _ := uuid.Parse(c.GetString("userID"))
+	if appliedShare.UserID != userID {
+		if _, _, err := h.accountPolicy.Authorize(c.Request.Context(), userID, appliedShare.AccountID, policies.ActionView); err != nil {
+			errorResp, statusCode := errors.GetErrorResponse(err)
+			c.JSON(statusCode, errorResp)
+			return
+		}
+	}
+
+	var appliedShareError *models.AppliedShareError
+	if len(attempts) > 0 {
+		appliedShareError = &attempts[len(attempts)-1]
+	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success", "applied_share": appliedShare, "applied_share_error": appliedShareError})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "applied_share": appliedShare, "applied_share_error": appliedShareError, "attempts": attempts})
 }
 
 func (h *shareHandler) MarkShareErrorsAsSeenByUserID(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionErrorsMarkSeen, "user", c.GetString("userID"), uuid.Nil)
+
 	userID := c.MustGet("userID").(string)
 	if userID == "" {
 		errResp := errors.ErrorResponse{
@@ -108,7 +138,12 @@ func (h *shareHandler) MarkShareErrorsAsSeenByUserID(c *gin.Context) {
 		return
 	}
 
-	if err := h.shareService.MarkShareErrorsAsSeenByUserID(userID); err != nil {
+	accountIDs, ok := h.accountIDs(c, userID, policies.ActionApply)
+	if !ok {
+		return
+	}
+
+	if err := h.shareService.MarkShareErrorsAsSeenByUserID(c.Request.Context(), userID, accountIDs); err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
 		return
@@ -117,99 +152,77 @@ func (h *shareHandler) MarkShareErrorsAsSeenByUserID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
 }
 
-func (h *shareHandler) ApplyShare() {
-	allAccounts, err := h.accountService.GetAllAccounts()
+func (h *shareHandler) RunApply(c *gin.Context) {
+	userID := c.GetString("userID")
+	userIDParsed, err := uuid.Parse(userID)
 	if err != nil {
-		logs.Error("Failed to get all accounts", map[string]any{"error": err})
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
+		}
+		c.JSON(http.StatusUnauthorized, errResp)
 		return
 	}
 
-	for _, account := range allAccounts {
-		if account.Status != "active" {
-			continue
-		}
-		if account.ExpiredDate.Before(time.Now()) {
-			h.accountService.SetAccountStatus(account.ID, "meroshare_expired")
-			continue
-		}
-		if account.PasswordExpiryDate.Before(time.Now()) {
-			h.accountService.SetAccountStatus(account.ID, "password_expired")
-			continue
-		}
-		bsDate := strings.Split(account.DMATExpiryDate, "-")
-		dmatExpiryDate, err := utils.ConvertBSToAD(utils.StringToInt(bsDate[0]), utils.StringToInt(bsDate[1]), utils.StringToInt(bsDate[2]))
-		if err != nil {
-			logs.Error("Failed to convert DMAT expiry date", map[string]any{"error": err})
-			continue
-		}
-		if dmatExpiryDate.Before(time.Now()) {
-			h.accountService.SetAccountStatus(account.ID, "dmat_expired")
-			continue
+	var req requests.ApplyRunRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "VALIDATION_ERROR",
+			Message: "Invalid query parameters",
+			Details: map[string]string{"error": err.Error()},
 		}
-		authorization, err := h.accountService.LoginAccount(account.ClientID, account.Username, account.Password)
+		c.JSON(http.StatusBadRequest, errResp)
+		return
+	}
+	// Submitting real applications has to be asked for explicitly, and is left to the worker.
+	if req.DryRun != nil && !*req.DryRun {
+		var jobID string
+		defer func() { recordAudit(c, h.auditService, services.AuditActionApplyRun, "job", jobID, uuid.Nil) }()
+
+		job, err := h.jobService.Enqueue(c.Request.Context(), services.JobTypeApply, userID, nil)
 		if err != nil {
-			if err.Error() == "invalid credentials" {
-				h.accountService.SetAccountStatus(account.ID, "invalid_credentials")
-				continue
-			}
-			logs.Error("Failed to get authorization header", map[string]any{"error": err})
-			continue
+			errorResp, statusCode := errors.GetErrorResponse(err)
+			c.JSON(statusCode, errorResp)
+			return
 		}
-		applicableShares, err := h.shareService.FetchApplicableShares(authorization)
-		if err != nil {
-			logs.Error("Failed to fetch applicable shares", map[string]any{"error": err})
-			continue
+		jobID = job.ID
+		c.JSON(http.StatusAccepted, gin.H{"status": "success", "job": job})
+		return
+	}
+
+	plan, err := h.applyService.RunForUser(c.Request.Context(), userIDParsed, true)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "plan": plan})
+}
+
+// accountIDs lists the accounts other users have shared with the user that allow action. The
+// user's own accounts are matched by user ID instead, so their history survives deleting them.
+func (h *shareHandler) accountIDs(c *gin.Context, userID string, action policies.Action) ([]uuid.UUID, bool) {
+	userIDParsed, err := uuid.Parse(userID)
+	if err != nil {
+		errResp := errors.ErrorResponse{
+			Type:    "UNAUTHORIZED",
+			Message: "User ID not found in context",
 		}
-		for _, share := range applicableShares.Shares {
-			alreadyApplied, err := h.shareService.CheckIfShareAlreadyApplied(account.ID.String(), fmt.Sprintf("%d", share.CompanyShareID))
-			if err != nil {
-				logs.Error("Failed to check if share already applied", map[string]any{"error": err})
-				continue
-			}
-			if share.Action == "" && share.ShareGroupName == "Ordinary Shares" && !alreadyApplied {
-				result, err := h.shareService.ApplyForShare(account, share, authorization)
-				if err != nil {
-					if err.Error() == "conflict: You have entered wrong transaction PIN." {
-						h.accountService.SetAccountStatus(account.ID, "invalid_pin")
-					}
-					logs.Error("Failed to apply for share", map[string]any{"error": err})
-					appliedShareId, er := h.shareService.AddAppliedShare(&models.AppliedShare{
-						UserID:         account.UserID,
-						AccountID:      account.ID,
-						CompanyName:    share.CompanyName,
-						CompanyShareID: share.CompanyShareID,
-						Scrip:          share.Scrip,
-						AppliedKitta:   account.PreferredKitta,
-						ShareGroupName: share.ShareGroupName,
-						ShareTypeName:  share.ShareTypeName,
-						SubGroup:       share.SubGroup,
-						Status:         "failed",
-					})
-					if er != nil {
-						logs.Error("Failed to add applied share", map[string]any{"error": er})
-					}
-					h.shareService.AddApplyShareError(&models.AppliedShareError{
-						UserID:         account.UserID,
-						AccountID:      account.ID,
-						AppliedShareID: appliedShareId,
-						Message:        err.Error(),
-					})
-					continue
-				}
-				logs.Info("Successfully applied for share", map[string]any{"result": result, "account_id": account.ID, "share_id": share.CompanyShareID})
-				h.shareService.AddAppliedShare(&models.AppliedShare{
-					UserID:         account.UserID,
-					AccountID:      account.ID,
-					CompanyName:    share.CompanyName,
-					CompanyShareID: share.CompanyShareID,
-					Scrip:          share.Scrip,
-					AppliedKitta:   account.PreferredKitta,
-					ShareGroupName: share.ShareGroupName,
-					ShareTypeName:  share.ShareTypeName,
-					SubGroup:       share.SubGroup,
-					Status:         "applied",
-				})
-			}
+		c.JSON(http.StatusUnauthorized, errResp)
+		return nil, false
+	}
+	accounts, _, err := h.accountPolicy.Accounts(c.Request.Context(), userIDParsed, action)
+	if err != nil {
+		errorResp, statusCode := errors.GetErrorResponse(err)
+		c.JSON(statusCode, errorResp)
+		return nil, false
+	}
+	var accountIDs []uuid.UUID
+	for _, account := range accounts {
+		if account.UserID != userIDParsed {
+			accountIDs = append(accountIDs, account.ID)
 		}
 	}
+	return accountIDs, true
 }
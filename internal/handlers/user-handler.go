@@ -15,12 +15,14 @@ type UserHandler interface {
 }
 
 type userHandler struct {
-	userService services.UserService
+	userService  services.UserService
+	auditService services.AuditService
 }
 
-func NewUserHandler(userService services.UserService) UserHandler {
+func NewUserHandler(userService services.UserService, auditService services.AuditService) UserHandler {
 	return &userHandler{
-		userService: userService,
+		userService:  userService,
+		auditService: auditService,
 	}
 }
 
@@ -44,7 +46,7 @@ func (h *userHandler) GetUserDashboard(c *gin.Context) {
 		return
 	}
 
-	dashboardData, err := h.userService.GetUserDashboard(userIDParsed)
+	dashboardData, err := h.userService.GetUserDashboard(c.Request.Context(), userIDParsed)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
@@ -55,6 +57,8 @@ func (h *userHandler) GetUserDashboard(c *gin.Context) {
 }
 
 func (h *userHandler) ResetUserLogs(c *gin.Context) {
+	defer recordAudit(c, h.auditService, services.AuditActionResetLogs, "user", c.GetString("userID"), uuid.Nil)
+
 	userID := c.GetString("userID")
 	if userID == "" {
 		errResp := errors.ErrorResponse{
@@ -74,7 +78,7 @@ func (h *userHandler) ResetUserLogs(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.ResetUserLogs(userIDParsed)
+	err = h.userService.ResetUserLogs(c.Request.Context(), userIDParsed)
 	if err != nil {
 		errorResp, statusCode := errors.GetErrorResponse(err)
 		c.JSON(statusCode, errorResp)
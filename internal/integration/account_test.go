@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+)
+
+func TestAccountCRUDOwnership(t *testing.T) {
+	h := newHarness(t)
+
+	aliceToken, _ := h.registerAndLogin("alice")
+	bobToken, _ := h.registerAndLogin("bob")
+
+	accountID := h.createAccount(aliceToken, "alice-meroshare")
+
+	status, resp := h.do(http.MethodGet, "/api/v1/accounts/"+accountID, aliceToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("get own account: expected 200, got %d: %v", status, resp)
+	}
+	account := resp["account"].(map[string]any)
+	if account["Name"] != "Mock Investor" || account["BOID"] != "00000001" {
+		t.Fatalf("get own account: details not fetched from MeroShare: %v", account)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts/"+accountID, bobToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("get other user's account: expected 403, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts", bobToken, nil)
+	if status != http.StatusOK || len(resp["accounts"].([]any)) != 0 {
+		t.Fatalf("list bob's accounts: expected none, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPut, "/api/v1/accounts/"+accountID, aliceToken, map[string]any{
+		"client_id":       101,
+		"username":        "alice-meroshare",
+		"password":        "meroshare-password",
+		"bank_id":         "54",
+		"crn_number":      "CRN0001",
+		"transaction_pin": "4321",
+		"preferred_kitta": 20,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("update own account: expected 200, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts/"+accountID, aliceToken, nil)
+	if status != http.StatusOK || resp["account"].(map[string]any)["PreferredKitta"] != "20" {
+		t.Fatalf("get updated account: expected preferred kitta 20, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodDelete, "/api/v1/accounts/"+accountID, bobToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("delete other user's account: expected 403, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodDelete, "/api/v1/accounts/"+accountID, aliceToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete own account: expected 200, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts", aliceToken, nil)
+	if status != http.StatusOK || len(resp["accounts"].([]any)) != 0 {
+		t.Fatalf("list after delete: expected none, got %d: %v", status, resp)
+	}
+}
+
+func TestRestoreDeletedAccount(t *testing.T) {
+	h := newHarness(t)
+
+	aliceToken, _ := h.registerAndLogin("alice")
+	bobToken, _ := h.registerAndLogin("bob")
+
+	accountID := h.createAccount(aliceToken, "alice-meroshare")
+	status, resp := h.do(http.MethodPost, "/api/v1/accounts/"+accountID+"/restore", aliceToken, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("restore live account: expected 404, got %d: %v", status, resp)
+	}
+
+	if status, resp := h.do(http.MethodDelete, "/api/v1/accounts/"+accountID, aliceToken, nil); status != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/accounts/"+accountID+"/restore", bobToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("restore other user's account: expected 403, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/accounts/"+accountID+"/restore", aliceToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("restore: expected 200, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts", aliceToken, nil)
+	if status != http.StatusOK || len(resp["accounts"].([]any)) != 1 {
+		t.Fatalf("list after restore: expected the account back, got %d: %v", status, resp)
+	}
+
+	// Once deleted, the same MeroShare login can be linked again, and the old account can then no
+	// longer be restored over it.
+	if status, resp := h.do(http.MethodDelete, "/api/v1/accounts/"+accountID, aliceToken, nil); status != http.StatusOK {
+		t.Fatalf("delete again: expected 200, got %d: %v", status, resp)
+	}
+	readded := h.createAccount(aliceToken, "alice-meroshare")
+	if readded == accountID {
+		t.Fatalf("expected a new account to be created, got the deleted one back")
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/accounts/"+accountID+"/restore", aliceToken, nil)
+	if status != http.StatusConflict {
+		t.Fatalf("restore over a re-added account: expected 409, got %d: %v", status, resp)
+	}
+}
+
+func TestPurgeDeletedAccountsAfterRetention(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	expired := h.createAccount(token, "expired-meroshare")
+	recent := h.createAccount(token, "recent-meroshare")
+	live := h.createAccount(token, "live-meroshare")
+	for _, id := range []string{expired, recent} {
+		if status, resp := h.do(http.MethodDelete, "/api/v1/accounts/"+id, token, nil); status != http.StatusOK {
+			t.Fatalf("delete: expected 200, got %d: %v", status, resp)
+		}
+	}
+	deletedAt := time.Now().Add(-h.cfg.AccountRetention - time.Hour)
+	if err := h.db.Unscoped().Model(&models.Account{}).Where("id = ?", expired).Update("deleted_at", deletedAt).Error; err != nil {
+		t.Fatalf("failed to backdate deletion: %v", err)
+	}
+
+	purged, err := h.app.AccountService.PurgeDeletedAccounts(context.Background())
+	if err != nil || purged != 1 {
+		t.Fatalf("purge: expected one account purged, got %d: %v", purged, err)
+	}
+
+	var remaining []models.Account
+	h.db.Unscoped().Order("username").Find(&remaining)
+	if len(remaining) != 2 || remaining[0].ID.String() != live || remaining[1].ID.String() != recent {
+		t.Fatalf("expected the live and recently deleted accounts to remain, got %+v", remaining)
+	}
+	status, resp := h.do(http.MethodPost, "/api/v1/accounts/"+expired+"/restore", token, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("restore purged account: expected 404, got %d: %v", status, resp)
+	}
+}
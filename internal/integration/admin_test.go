@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+)
+
+func TestAdminEndpoints(t *testing.T) {
+	h := newHarness(t)
+
+	adminToken, _ := h.registerAndLogin("admin")
+	aliceToken, aliceRefresh := h.registerAndLogin("alice")
+	h.createAccount(aliceToken, "alice-meroshare")
+
+	status, resp := h.do(http.MethodGet, "/api/v1/admin/users", aliceToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("list users as non-admin: expected 403, got %d: %v", status, resp)
+	}
+	if err := h.db.Model(&models.User{}).Where("username = ?", "admin").Update("role", models.UserRoleAdmin).Error; err != nil {
+		t.Fatalf("failed to promote admin: %v", err)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/admin/users", adminToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("list users: expected 200, got %d: %v", status, resp)
+	}
+	users := resp["users"].([]any)
+	if len(users) != 2 {
+		t.Fatalf("list users: expected 2 users, got %v", users)
+	}
+	alice := users[1].(map[string]any)
+	if alice["username"] != "alice" || alice["role"] != "user" || alice["password"] != nil {
+		t.Fatalf("list users: unexpected user %v", alice)
+	}
+	aliceID := alice["id"].(string)
+
+	status, resp = h.do(http.MethodGet, "/api/v1/admin/accounts/stats", adminToken, nil)
+	if status != http.StatusOK || resp["accounts_by_status"].(map[string]any)["active"] != float64(1) {
+		t.Fatalf("account stats: expected one active account, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/admin/runs", adminToken, map[string]any{"type": "verify"})
+	if status != http.StatusBadRequest {
+		t.Fatalf("trigger unknown run: expected 400, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/admin/runs", adminToken, map[string]any{"type": "sync"})
+	if status != http.StatusAccepted || resp["job"].(map[string]any)["type"] != "sync" {
+		t.Fatalf("trigger sync: expected 202, got %d: %v", status, resp)
+	}
+	jobID := resp["job"].(map[string]any)["id"].(string)
+	h.runJobs()
+	status, resp = h.do(http.MethodGet, "/api/v1/admin/jobs/"+jobID, adminToken, nil)
+	if status != http.StatusOK || resp["job"].(map[string]any)["status"] != "succeeded" {
+		t.Fatalf("admin job: expected the triggered sync to have succeeded, got %d: %v", status, resp)
+	}
+	if status, resp = h.do(http.MethodGet, "/api/v1/admin/jobs/"+jobID, aliceToken, nil); status != http.StatusForbidden {
+		t.Fatalf("admin job as non-admin: expected 403, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/admin/cdsc/errors?window=48h", adminToken, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("cdsc errors over 48h: expected 400, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/admin/cdsc/errors?window=15m", adminToken, nil)
+	if status != http.StatusOK || len(resp["endpoints"].([]any)) == 0 {
+		t.Fatalf("cdsc errors: expected the calls made for alice's account, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/admin/users/"+aliceID+"/disable", adminToken, nil)
+	if status != http.StatusOK || resp["user"].(map[string]any)["disabled_at"] == nil {
+		t.Fatalf("disable alice: expected 200, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts", aliceToken, nil)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("token of disabled user: expected 401, got %d: %v", status, resp)
+	}
+	login := map[string]any{"identifier": "alice", "password": "secret123"}
+	status, resp = h.do(http.MethodPost, "/api/v1/login", "", login)
+	if status != http.StatusForbidden {
+		t.Fatalf("login as disabled user: expected 403, got %d: %v", status, resp)
+	}
+	refresh := map[string]any{"refresh_token": aliceRefresh}
+	status, resp = h.do(http.MethodPost, "/api/v1/refresh", "", refresh)
+	if status != http.StatusForbidden {
+		t.Fatalf("refresh as disabled user: expected 403, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/admin/users/"+aliceID+"/enable", adminToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("enable alice: expected 200, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts", aliceToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("token of re-enabled user: expected 200, got %d: %v", status, resp)
+	}
+	if status, resp = h.do(http.MethodPost, "/api/v1/login", "", login); status != http.StatusOK {
+		t.Fatalf("login as re-enabled user: expected 200, got %d: %v", status, resp)
+	}
+	if status, resp = h.do(http.MethodPost, "/api/v1/refresh", "", refresh); status != http.StatusOK {
+		t.Fatalf("refresh token kept while disabled: expected 200, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/audit?action=admin.disable_user", adminToken, nil)
+	if status != http.StatusOK || len(resp["events"].([]any)) != 1 {
+		t.Fatalf("audit: expected the disable to be recorded, got %d: %v", status, resp)
+	}
+	event := resp["events"].([]any)[0].(map[string]any)
+	if event["Outcome"] != "success" || event["TargetID"] != aliceID {
+		t.Fatalf("audit: unexpected disable event %v", event)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/audit?action=admin.access", aliceToken, nil)
+	if status != http.StatusOK || len(resp["events"].([]any)) != 2 || resp["events"].([]any)[0].(map[string]any)["Outcome"] != "denied" {
+		t.Fatalf("audit: expected both of alice's refused admin requests to be recorded, got %d: %v", status, resp)
+	}
+}
+
+func TestAdminCannotDisableThemselves(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("admin")
+	var admin models.User
+	if err := h.db.Where("username = ?", "admin").First(&admin).Error; err != nil {
+		t.Fatalf("failed to load admin: %v", err)
+	}
+	if err := h.db.Model(&admin).Update("role", models.UserRoleAdmin).Error; err != nil {
+		t.Fatalf("failed to promote admin: %v", err)
+	}
+
+	status, resp := h.do(http.MethodPost, "/api/v1/admin/users/"+admin.ID.String()+"/disable", token, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("disable self: expected 400, got %d: %v", status, resp)
+	}
+}
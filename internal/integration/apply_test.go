@@ -0,0 +1,299 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/pkg/meroshare/mock"
+)
+
+func TestApplyRunAppliesOnce(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	h.app.ApplyService.Run(context.Background())
+
+	applications := h.meroshare.Applications()
+	if len(applications) != 1 {
+		t.Fatalf("expected 1 application, got %d", len(applications))
+	}
+	if applications[0].AppliedKitta != "10" || applications[0].TransactionPIN != "1234" {
+		t.Fatalf("unexpected application %+v", applications[0])
+	}
+
+	var shares []models.AppliedShare
+	h.db.Find(&shares)
+	if len(shares) != 1 || shares[0].Status != "applied" || shares[0].Amount != 1000 {
+		t.Fatalf("expected one applied share costing 1000, got %+v", shares)
+	}
+
+	h.app.ApplyService.Run(context.Background())
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("second run re-applied: expected 1 application, got %d", got)
+	}
+
+	status, resp := h.do(http.MethodGet, "/api/v1/issues?status=open", token, nil)
+	if status != http.StatusOK || len(resp["issues"].([]any)) != 1 {
+		t.Fatalf("issue calendar: expected the mock issue, got %d: %v", status, resp)
+	}
+}
+
+func TestApplyRunSkipsIPOBeyondBalance(t *testing.T) {
+	h := newHarness(t, mock.WithBalance(500))
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+
+	h.app.ApplyService.Run(context.Background())
+
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("expected the IPO costing 1000 not to be applied for with 500, got %d applications", got)
+	}
+	var share models.AppliedShare
+	h.db.First(&share, "account_id = ?", accountID)
+	if share.Status != "skipped_insufficient_funds" || share.Amount != 1000 {
+		t.Fatalf("expected the IPO to be skipped for insufficient funds, got %+v", share)
+	}
+}
+
+func TestApplyRunFallsBackToEnteredBalanceWhenBankFails(t *testing.T) {
+	h := newHarness(t, mock.WithBalance(100000), mock.WithScenario(mock.ScenarioBalanceUnavailable))
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+	if err := h.db.Model(&models.Account{}).Where("id = ?", accountID).Update("available_balance", 500).Error; err != nil {
+		t.Fatalf("failed to set the entered balance: %v", err)
+	}
+
+	h.app.ApplyService.Run(context.Background())
+
+	var share models.AppliedShare
+	h.db.First(&share, "account_id = ?", accountID)
+	if share.Status != "skipped_insufficient_funds" || !strings.Contains(share.Remark, "only NPR 500.00") {
+		t.Fatalf("expected the entered balance of 500 to be used after the bank returned 500, got %+v", share)
+	}
+}
+
+func TestApplyRunSkipsWhenNoBalanceIsKnown(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+	if err := h.db.Model(&models.Account{}).Where("id = ?", accountID).Update("available_balance", nil).Error; err != nil {
+		t.Fatalf("failed to clear the entered balance: %v", err)
+	}
+
+	h.app.ApplyService.Run(context.Background())
+
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("expected nothing applied without a known balance, got %d applications", got)
+	}
+	var share models.AppliedShare
+	h.db.First(&share, "account_id = ?", accountID)
+	if share.Status != "skipped_insufficient_funds" {
+		t.Fatalf("expected the IPO to be skipped for insufficient funds, got %+v", share)
+	}
+}
+
+func rightShareIssue() mock.Issue {
+	issue := mock.DefaultIssues()[0]
+	issue.ShareTypeName = "RIGHT SHARE"
+	issue.SubGroup = "For Existing Shareholders"
+	issue.EligibleKitta = 25
+	return issue
+}
+
+func TestApplyRunAppliesEligibleRightShareKitta(t *testing.T) {
+	h := newHarness(t, mock.WithIssues(rightShareIssue()))
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	h.app.ApplyService.Run(context.Background())
+
+	applications := h.meroshare.Applications()
+	if len(applications) != 1 || applications[0].AppliedKitta != "25" {
+		t.Fatalf("expected the 25 eligible right share units to be applied for, got %+v", applications)
+	}
+}
+
+func TestRightShareEligibilityFailureIsReportedAsError(t *testing.T) {
+	h := newHarness(t, mock.WithIssues(rightShareIssue()), mock.WithScenario(mock.ScenarioRightUnavailable))
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/shares/apply", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("dry run: expected 200, got %d: %v", status, resp)
+	}
+	issues := resp["plan"].(map[string]any)["accounts"].([]any)[0].(map[string]any)["issues"].([]any)
+	if len(issues) != 1 || issues[0].(map[string]any)["action"] != "error" {
+		t.Fatalf("expected the right share to be reported as an error, got %v", issues)
+	}
+}
+
+func TestApplyRunSkipsDisabledUsers(t *testing.T) {
+	h := newHarness(t)
+
+	aliceToken, _ := h.registerAndLogin("alice")
+	h.createAccount(aliceToken, "alice-meroshare")
+	bobToken, _ := h.registerAndLogin("bob")
+	bobAccountID := h.createAccount(bobToken, "bob-meroshare")
+	if err := h.db.Model(&models.User{}).Where("username = ?", "bob").Update("disabled_at", time.Now()).Error; err != nil {
+		t.Fatalf("failed to disable bob: %v", err)
+	}
+
+	h.app.ApplyService.Run(context.Background())
+
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("expected only alice's account to apply, got %d applications", got)
+	}
+	var count int64
+	h.db.Model(&models.AppliedShare{}).Where("account_id = ?", bobAccountID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected nothing applied for a disabled user's account, got %d", count)
+	}
+}
+
+func TestApplyRunRecordsWrongPIN(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+
+	h.meroshare.SetScenario(mock.ScenarioWrongPIN)
+	h.app.ApplyService.Run(context.Background())
+
+	var account models.Account
+	h.db.First(&account, "id = ?", accountID)
+	if account.Status != "invalid_pin" {
+		t.Fatalf("expected account status invalid_pin, got %q", account.Status)
+	}
+
+	var share models.AppliedShare
+	h.db.First(&share, "account_id = ?", accountID)
+	if share.Status != "failed" || !share.Permanent {
+		t.Fatalf("expected a permanent failure, got %+v", share)
+	}
+
+	status, resp := h.do(http.MethodGet, "/api/v1/dashboard", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("dashboard: expected 200, got %d: %v", status, resp)
+	}
+	dashboard := resp["dashboard"].(map[string]any)
+	for key, want := range map[string]float64{
+		"total_accounts":      1,
+		"accounts_with_issue": 1,
+		"total_shares":        1,
+		"failed_shares":       1,
+	} {
+		if dashboard[key] != want {
+			t.Errorf("dashboard %s: expected %v, got %v", key, want, dashboard[key])
+		}
+	}
+	if failed := dashboard["failed_applications"].([]any); len(failed) != 1 {
+		t.Fatalf("dashboard: expected 1 failed application, got %v", failed)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/reset-logs", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("reset logs: expected 200, got %d: %v", status, resp)
+	}
+	_, resp = h.do(http.MethodGet, "/api/v1/dashboard", token, nil)
+	if total := resp["dashboard"].(map[string]any)["total_shares"]; total != float64(0) {
+		t.Fatalf("dashboard after reset: expected 0 shares, got %v", total)
+	}
+}
+
+func TestResetLogsKeepsAppliedShares(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+	h.app.ApplyService.Run(context.Background())
+
+	status, resp := h.do(http.MethodPost, "/api/v1/reset-logs", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("reset logs: expected 200, got %d: %v", status, resp)
+	}
+	var count int64
+	h.db.Model(&models.AppliedShare{}).Where("status = ?", "applied").Count(&count)
+	if count != 1 {
+		t.Fatalf("expected the applied share to survive the reset, got %d", count)
+	}
+
+	h.app.ApplyService.Run(context.Background())
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("run after reset re-applied: expected 1 application, got %d", got)
+	}
+}
+
+func TestApplyRetriesTransientFailure(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	h.meroshare.SetScenario(mock.ScenarioApplyUnavailable)
+	h.app.ApplyService.Run(context.Background())
+
+	h.meroshare.SetScenario(mock.ScenarioHappy)
+	h.app.ApplyService.Run(context.Background())
+
+	var share models.AppliedShare
+	h.db.First(&share)
+	if share.Status != "applied" || share.Attempts != 2 {
+		t.Fatalf("expected applied on the second attempt, got %+v", share)
+	}
+
+	var attempts []models.AppliedShareError
+	h.db.Find(&attempts, "applied_share_id = ?", share.ID)
+	if len(attempts) != 1 || attempts[0].Permanent {
+		t.Fatalf("expected one transient failure in the history, got %+v", attempts)
+	}
+}
+
+func TestDryRunDoesNotApply(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/shares/apply", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("dry run: expected 200, got %d: %v", status, resp)
+	}
+	plan := resp["plan"].(map[string]any)
+	issues := plan["accounts"].([]any)[0].(map[string]any)["issues"].([]any)
+	if plan["dry_run"] != true || len(issues) != 1 || issues[0].(map[string]any)["action"] != "apply" {
+		t.Fatalf("dry run: unexpected plan %v", plan)
+	}
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("dry run submitted %d applications", got)
+	}
+
+	var count int64
+	h.db.Model(&models.AppliedShare{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("dry run recorded %d applied shares", count)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/shares/apply?dry_run=false", token, nil)
+	if status != http.StatusAccepted {
+		t.Fatalf("live run: expected 202, got %d: %v", status, resp)
+	}
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("live run applied %d times before a worker picked it up", got)
+	}
+	h.runJobs()
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("live run: expected 1 application, got %d", got)
+	}
+}
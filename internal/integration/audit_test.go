@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+)
+
+func TestAuditLogRecordsSecurityRelevantActions(t *testing.T) {
+	h := newHarness(t)
+
+	token, refreshToken := h.registerAndLogin("alice")
+	bobToken, _ := h.registerAndLogin("bob")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/login", "", map[string]any{
+		"identifier": "alice",
+		"password":   "wrong-password",
+	})
+	if status != http.StatusUnauthorized {
+		t.Fatalf("bad password: expected 401, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/refresh", "", map[string]any{"refresh_token": refreshToken})
+	if status != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d: %v", status, resp)
+	}
+
+	accountID := h.createAccount(token, "alice-meroshare")
+	status, resp = h.do(http.MethodDelete, "/api/v1/accounts/"+accountID, bobToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("delete by another user: expected 403, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodDelete, "/api/v1/accounts/"+accountID, token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/reset-logs", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("reset logs: expected 200, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/audit", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("audit: expected 200, got %d: %v", status, resp)
+	}
+	type event struct{ action, outcome, target string }
+	var got []event
+	for _, e := range resp["events"].([]any) {
+		e := e.(map[string]any)
+		got = append(got, event{e["Action"].(string), e["Outcome"].(string), e["TargetID"].(string)})
+	}
+	want := []event{
+		{"user.reset_logs", "success", ""},
+		{"account.delete", "success", accountID},
+		{"account.create", "success", accountID},
+		{"auth.refresh_token", "success", ""},
+		{"auth.login", "denied", ""},
+		{"auth.login", "success", ""},
+		{"auth.register", "success", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i].action != want[i].action || got[i].outcome != want[i].outcome || (want[i].target != "" && got[i].target != want[i].target) {
+			t.Fatalf("event %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	// Bob sees his own denied delete, but none of alice's events.
+	status, resp = h.do(http.MethodGet, "/api/v1/audit?action=account.delete", bobToken, nil)
+	events := resp["events"].([]any)
+	if status != http.StatusOK || len(events) != 1 || events[0].(map[string]any)["Outcome"] != "denied" {
+		t.Fatalf("bob's audit: expected one denied delete, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/audit?action=auth.login&limit=1", token, nil)
+	if status != http.StatusOK || len(resp["events"].([]any)) != 1 {
+		t.Fatalf("filtered audit: expected one event, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/audit?limit=1000", token, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("oversized limit: expected 400, got %d: %v", status, resp)
+	}
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	h := newHarness(t)
+	h.registerAndLogin("alice")
+
+	if err := h.db.Model(&models.AuditEvent{}).Where("1 = 1").Update("outcome", "denied").Error; err == nil {
+		t.Fatal("expected audit events to reject updates")
+	}
+	if err := h.db.Where("1 = 1").Delete(&models.AuditEvent{}).Error; err == nil {
+		t.Fatal("expected audit events to reject deletes")
+	}
+	var count int64
+	h.db.Model(&models.AuditEvent{}).Where("outcome = ?", "success").Count(&count)
+	if count != 2 {
+		t.Fatalf("expected the register and login events to be untouched, got %d", count)
+	}
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegisterLoginAndRefresh(t *testing.T) {
+	h := newHarness(t)
+
+	accessToken, refreshToken := h.registerAndLogin("alice")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/register", "", map[string]any{
+		"username":   "alice",
+		"password":   "secret123",
+		"email":      "other@example.com",
+		"first_name": "alice",
+		"last_name":  "again",
+	})
+	if status != http.StatusConflict {
+		t.Fatalf("duplicate register: expected 409, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/login", "", map[string]any{
+		"identifier": "alice@example.com",
+		"password":   "wrong-password",
+	})
+	if status != http.StatusUnauthorized {
+		t.Fatalf("bad password: expected 401, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/profile", accessToken, nil)
+	if status != http.StatusOK {
+		t.Fatalf("profile: expected 200, got %d: %v", status, resp)
+	}
+	profile := resp["profile"].(map[string]any)
+	if profile["username"] != "alice" || profile["first_name"] != "Test" {
+		t.Fatalf("profile: unexpected body %v", profile)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/profile", "", nil)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("profile without token: expected 401, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/refresh", "", map[string]any{"refresh_token": refreshToken})
+	if status != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d: %v", status, resp)
+	}
+	if resp["access_token"] == "" || resp["refresh_token"] == refreshToken {
+		t.Fatalf("refresh: expected a new token pair, got %v", resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/refresh", "", map[string]any{"refresh_token": refreshToken})
+	if status != http.StatusForbidden {
+		t.Fatalf("reused refresh token: expected 403, got %d: %v", status, resp)
+	}
+}
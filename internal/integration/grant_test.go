@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAccountGrantsShareAccessByRole(t *testing.T) {
+	h := newHarness(t)
+
+	aliceToken, _ := h.registerAndLogin("alice")
+	bobToken, _ := h.registerAndLogin("bob")
+	carolToken, _ := h.registerAndLogin("carol")
+	daveToken, _ := h.registerAndLogin("dave")
+	accountID := h.createAccount(aliceToken, "alice-meroshare")
+	grantsPath := "/api/v1/accounts/" + accountID + "/grants"
+
+	status, resp := h.do(http.MethodPost, grantsPath, aliceToken, map[string]any{"identifier": "bob@example.com", "role": "viewer"})
+	if status != http.StatusCreated {
+		t.Fatalf("invite bob: expected 201, got %d: %v", status, resp)
+	}
+	bobGrant := resp["grant"].(map[string]any)["ID"].(string)
+	status, resp = h.do(http.MethodPost, grantsPath, aliceToken, map[string]any{"identifier": "carol", "role": "manager"})
+	if status != http.StatusCreated {
+		t.Fatalf("invite carol: expected 201, got %d: %v", status, resp)
+	}
+	carolGrant := resp["grant"].(map[string]any)["ID"].(string)
+
+	for _, tc := range []struct {
+		name, identifier, role string
+		want                   int
+	}{
+		{"again", "bob", "manager", http.StatusConflict},
+		{"the owner", "alice", "viewer", http.StatusConflict},
+		{"an unknown user", "nobody", "viewer", http.StatusNotFound},
+		{"with an unknown role", "dave", "admin", http.StatusBadRequest},
+	} {
+		status, resp := h.do(http.MethodPost, grantsPath, aliceToken, map[string]any{"identifier": tc.identifier, "role": tc.role})
+		if status != tc.want {
+			t.Fatalf("invite %s: expected %d, got %d: %v", tc.name, tc.want, status, resp)
+		}
+	}
+
+	// An invitation gives no access until it is accepted, and only by the invited user.
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts/"+accountID, bobToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("get account with a pending invite: expected 403, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/grants", bobToken, nil)
+	if status != http.StatusOK || len(resp["grants"].([]any)) != 1 {
+		t.Fatalf("list bob's grants: expected the invitation, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/grants/"+bobGrant+"/accept", carolToken, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("accept someone else's invite: expected 404, got %d: %v", status, resp)
+	}
+	for token, grant := range map[string]string{bobToken: bobGrant, carolToken: carolGrant} {
+		if status, resp := h.do(http.MethodPost, "/api/v1/grants/"+grant+"/accept", token, nil); status != http.StatusOK {
+			t.Fatalf("accept: expected 200, got %d: %v", status, resp)
+		}
+	}
+
+	// A viewer sees the account without its credentials, and can change nothing.
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts/"+accountID, bobToken, nil)
+	if status != http.StatusOK || resp["role"] != "viewer" {
+		t.Fatalf("viewer get account: expected 200 as viewer, got %d: %v", status, resp)
+	}
+	if account := resp["account"].(map[string]any); account["Password"] != "" || account["TransactionPIN"] != "" || account["CRNNumber"] != "" {
+		t.Fatalf("viewer get account: expected credentials to be hidden, got %v", account)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts", bobToken, nil)
+	if status != http.StatusOK || len(resp["accounts"].([]any)) != 1 || resp["roles"].(map[string]any)[accountID] != "viewer" {
+		t.Fatalf("viewer list accounts: expected the shared account, got %d: %v", status, resp)
+	}
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPut, "/api/v1/accounts/" + accountID},
+		{http.MethodPost, "/api/v1/accounts/" + accountID + "/verify"},
+		{http.MethodDelete, "/api/v1/accounts/" + accountID},
+		{http.MethodGet, grantsPath},
+	} {
+		if status, resp := h.do(req.method, req.path, bobToken, map[string]any{}); status != http.StatusForbidden {
+			t.Fatalf("viewer %s %s: expected 403, got %d: %v", req.method, req.path, status, resp)
+		}
+	}
+
+	// A manager sees the credentials and can apply for the account, but not share or delete it.
+	status, resp = h.do(http.MethodGet, "/api/v1/accounts/"+accountID, carolToken, nil)
+	if status != http.StatusOK || resp["account"].(map[string]any)["TransactionPIN"] != "1234" {
+		t.Fatalf("manager get account: expected the credentials, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodPost, "/api/v1/shares/apply", carolToken, nil)
+	if status != http.StatusOK || len(resp["plan"].(map[string]any)["accounts"].([]any)) != 1 {
+		t.Fatalf("manager dry run: expected a plan for the shared account, got %d: %v", status, resp)
+	}
+	for _, req := range []struct{ method, path string }{
+		{http.MethodDelete, "/api/v1/accounts/" + accountID},
+		{http.MethodGet, grantsPath},
+	} {
+		if status, resp := h.do(req.method, req.path, carolToken, nil); status != http.StatusForbidden {
+			t.Fatalf("manager %s %s: expected 403, got %d: %v", req.method, req.path, status, resp)
+		}
+	}
+
+	// Results of the account are visible to everyone it is shared with, and nobody else.
+	h.app.ApplyService.Run(context.Background())
+	status, resp = h.do(http.MethodGet, "/api/v1/shares/applied", bobToken, nil)
+	if status != http.StatusOK || len(resp["applied_shares"].([]any)) != 1 {
+		t.Fatalf("viewer applied shares: expected the account's application, got %d: %v", status, resp)
+	}
+	shareID := resp["applied_shares"].([]any)[0].(map[string]any)["ID"].(string)
+	if status, resp := h.do(http.MethodGet, "/api/v1/shares/"+shareID, bobToken, nil); status != http.StatusOK {
+		t.Fatalf("viewer get share: expected 200, got %d: %v", status, resp)
+	}
+	if status, resp := h.do(http.MethodGet, "/api/v1/shares/"+shareID, daveToken, nil); status != http.StatusForbidden {
+		t.Fatalf("stranger get share: expected 403, got %d: %v", status, resp)
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/shares/applied", daveToken, nil)
+	if status != http.StatusOK || len(resp["applied_shares"].([]any)) != 0 {
+		t.Fatalf("stranger applied shares: expected none, got %d: %v", status, resp)
+	}
+
+	// The owner can revoke access, and a grantee can give it up.
+	status, resp = h.do(http.MethodGet, grantsPath, aliceToken, nil)
+	if status != http.StatusOK || len(resp["grants"].([]any)) != 2 {
+		t.Fatalf("owner list grants: expected 2, got %d: %v", status, resp)
+	}
+	if status, resp := h.do(http.MethodDelete, grantsPath+"/"+bobGrant, aliceToken, nil); status != http.StatusOK {
+		t.Fatalf("revoke: expected 200, got %d: %v", status, resp)
+	}
+	if status, resp := h.do(http.MethodDelete, "/api/v1/grants/"+carolGrant, carolToken, nil); status != http.StatusOK {
+		t.Fatalf("leave: expected 200, got %d: %v", status, resp)
+	}
+	for _, token := range []string{bobToken, carolToken} {
+		if status, resp := h.do(http.MethodGet, "/api/v1/accounts/"+accountID, token, nil); status != http.StatusForbidden {
+			t.Fatalf("get account after losing access: expected 403, got %d: %v", status, resp)
+		}
+	}
+	status, resp = h.do(http.MethodGet, "/api/v1/shares/applied", bobToken, nil)
+	if status != http.StatusOK || len(resp["applied_shares"].([]any)) != 0 {
+		t.Fatalf("applied shares after revoke: expected none, got %d: %v", status, resp)
+	}
+}
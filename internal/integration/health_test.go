@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthReportsLeadership(t *testing.T) {
+	h := newHarness(t)
+
+	status, resp := h.do(http.MethodGet, "/healthz", "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("healthz: expected 200, got %d: %v", status, resp)
+	}
+	leader := resp["leader"].(map[string]any)
+	if leader["identity"] != h.cfg.InstanceID || leader["is_leader"] != false {
+		t.Fatalf("healthz: expected a follower that has not started electing, got %v", leader)
+	}
+}
+
+func readinessCheck(t *testing.T, resp map[string]any, name string) map[string]any {
+	t.Helper()
+	for _, check := range resp["readiness"].(map[string]any)["checks"].([]any) {
+		if check := check.(map[string]any); check["name"] == name {
+			return check
+		}
+	}
+	t.Fatalf("readyz: no %s check in %v", name, resp)
+	return nil
+}
+
+func TestReadinessChecks(t *testing.T) {
+	h := newHarness(t)
+
+	// Nothing holds the leader lease yet, which only degrades readiness.
+	status, resp := h.do(http.MethodGet, "/readyz", "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("readyz: expected 200, got %d: %v", status, resp)
+	}
+	for _, name := range []string{"database", "redis", "migrations"} {
+		if check := readinessCheck(t, resp, name); check["status"] != "ok" || check["critical"] != true {
+			t.Fatalf("readyz: expected %s to pass, got %v", name, check)
+		}
+	}
+	if check := readinessCheck(t, resp, "scheduler"); check["status"] != "failed" {
+		t.Fatalf("readyz: expected the scheduler check to fail without a leader, got %v", check)
+	}
+	if resp["readiness"].(map[string]any)["degraded"] != true {
+		t.Fatalf("readyz: expected a degraded report, got %v", resp)
+	}
+
+	h.redis.Set(context.Background(), "meroshare:scheduler:leader", "other-replica", time.Minute)
+	h.app.HealthService.RecordSchedulerRun(time.Now())
+	_, resp = h.do(http.MethodGet, "/readyz", "", nil)
+	if check := readinessCheck(t, resp, "scheduler"); check["status"] != "ok" {
+		t.Fatalf("readyz: expected a recent run to pass, got %v", check)
+	}
+
+	h.app.HealthService.RecordSchedulerRun(time.Now().Add(-72 * time.Hour))
+	_, resp = h.do(http.MethodGet, "/readyz", "", nil)
+	if check := readinessCheck(t, resp, "scheduler"); check["status"] != "failed" {
+		t.Fatalf("readyz: expected a missed run to fail, got %v", check)
+	}
+
+	// The worker's ops router answers the same probes.
+	rec := httptest.NewRecorder()
+	h.app.OpsRouter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ops readyz: expected 200, got %d", rec.Code)
+	}
+}
+
+func TestReadinessFailsWithoutRedis(t *testing.T) {
+	h := newHarness(t)
+
+	h.redis.Close()
+	status, resp := h.do(http.MethodGet, "/readyz", "", nil)
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("readyz: expected 503, got %d: %v", status, resp)
+	}
+	if check := readinessCheck(t, resp, "redis"); check["status"] != "failed" || check["error"] == "" {
+		t.Fatalf("readyz: expected the redis check to fail, got %v", check)
+	}
+
+	status, _ = h.do(http.MethodGet, "/healthz", "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("healthz: expected liveness to ignore dependencies, got %d", status)
+	}
+}
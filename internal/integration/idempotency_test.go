@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	pkgredis "github.com/asrma7/meroshare-bot/pkg/redis"
+)
+
+func TestConcurrentRunsApplyOnce(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.app.ApplyService.Run(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("expected 1 application across concurrent runs, got %d", got)
+	}
+	var count int64
+	h.db.Model(&models.AppliedShare{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected 1 applied share row, got %d", count)
+	}
+}
+
+func TestRunSkipsLockedAccount(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+
+	lock, err := pkgredis.AcquireLock(context.Background(), h.redis, "meroshare:apply:account:"+accountID, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to take account lock: %v", err)
+	}
+
+	plan := h.app.ApplyService.Run(context.Background())
+	if len(plan.Accounts) != 1 || plan.Accounts[0].Status != "skipped" {
+		t.Fatalf("expected the locked account to be skipped, got %+v", plan.Accounts)
+	}
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("locked account was applied for %d times", got)
+	}
+
+	if err := lock.Release(context.Background()); err != nil {
+		t.Fatalf("failed to release account lock: %v", err)
+	}
+	h.app.ApplyService.Run(context.Background())
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("expected 1 application after the lock was released, got %d", got)
+	}
+}
+
+func TestInFlightApplicationIsNotResubmitted(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+
+	var account models.Account
+	h.db.First(&account, "id = ?", accountID)
+	inFlight := &models.AppliedShare{
+		UserID:         account.UserID,
+		AccountID:      account.ID,
+		CompanyName:    "Mock Hydropower Limited",
+		CompanyShareID: 700,
+		Scrip:          "MOCK",
+		AppliedKitta:   "10",
+		ShareGroupName: "Ordinary Shares",
+		ShareTypeName:  "IPO",
+		SubGroup:       "For General Public",
+		Status:         "in_flight",
+	}
+	if err := h.db.Create(inFlight).Error; err != nil {
+		t.Fatalf("failed to insert in-flight row: %v", err)
+	}
+
+	duplicate := *inFlight
+	duplicate.Status = "applied"
+	if err := h.db.Create(&duplicate).Error; err == nil {
+		t.Fatal("expected the unique index to reject a second row for the same account and issue")
+	}
+
+	h.app.ApplyService.Run(context.Background())
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("in-flight application was resubmitted %d times", got)
+	}
+}
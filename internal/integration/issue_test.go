@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/responses"
+)
+
+func TestIssueCalendarStatusesAndFilters(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+
+	kathmandu, _ := time.LoadLocation("Asia/Kathmandu")
+	now := time.Now().In(kathmandu)
+	date := func(days int) string {
+		return now.AddDate(0, 0, days).Format("Jan 2, 2006 3:04:05 PM")
+	}
+
+	if err := h.app.IssueService.SyncIssues(context.Background(), []responses.ApplicableShare{
+		{CompanyShareID: 1, Scrip: "OPEN", CompanyName: "Open Ltd", ShareTypeName: "IPO", ShareGroupName: "Ordinary Shares", IssueOpenDate: date(-1), IssueCloseDate: date(2)},
+		{CompanyShareID: 2, Scrip: "SOON", CompanyName: "Soon Ltd", ShareTypeName: "IPO", ShareGroupName: "Ordinary Shares", IssueOpenDate: date(5), IssueCloseDate: date(9)},
+		{CompanyShareID: 3, Scrip: "DONE", CompanyName: "Done Ltd", ShareTypeName: "FPO", ShareGroupName: "Ordinary Shares", IssueOpenDate: date(-9), IssueCloseDate: date(-2)},
+	}); err != nil {
+		t.Fatalf("sync issues: %v", err)
+	}
+
+	scrips := func(query string) []string {
+		t.Helper()
+		status, resp := h.do(http.MethodGet, "/api/v1/issues"+query, token, nil)
+		if status != http.StatusOK {
+			t.Fatalf("issues%s: expected 200, got %d: %v", query, status, resp)
+		}
+		var result []string
+		for _, issue := range resp["issues"].([]any) {
+			result = append(result, issue.(map[string]any)["Scrip"].(string))
+		}
+		return result
+	}
+
+	for query, want := range map[string]string{
+		"?status=open":     "OPEN",
+		"?status=upcoming": "SOON",
+		"?status=closed":   "DONE",
+		"?share_type=fpo":  "DONE",
+		"?from=" + now.AddDate(0, 0, 3).Format("2006-01-02") + "&to=" + now.AddDate(0, 0, 6).Format("2006-01-02"): "SOON",
+	} {
+		got := scrips(query)
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("issues%s: expected [%s], got %v", query, want, got)
+		}
+	}
+
+	if err := h.app.IssueService.SyncIssues(context.Background(), []responses.ApplicableShare{
+		{CompanyShareID: 2, Scrip: "SOON", CompanyName: "Soon Ltd", ShareTypeName: "IPO", ShareGroupName: "Ordinary Shares", IssueOpenDate: date(-1), IssueCloseDate: date(3)},
+	}); err != nil {
+		t.Fatalf("resync issues: %v", err)
+	}
+	if got := scrips("?status=open"); len(got) != 2 {
+		t.Fatalf("after resync: expected 2 open issues, got %v", got)
+	}
+}
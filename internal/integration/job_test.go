@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+)
+
+func TestApplyJobLifecycle(t *testing.T) {
+	h := newHarness(t)
+
+	aliceToken, _ := h.registerAndLogin("alice")
+	bobToken, _ := h.registerAndLogin("bob")
+	h.createAccount(aliceToken, "alice-meroshare")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/shares/apply?dry_run=false", aliceToken, nil)
+	if status != http.StatusAccepted {
+		t.Fatalf("enqueue apply: expected 202, got %d: %v", status, resp)
+	}
+	jobID := resp["job"].(map[string]any)["id"].(string)
+
+	status, resp = h.do(http.MethodGet, "/api/v1/jobs/"+jobID, aliceToken, nil)
+	if status != http.StatusOK || resp["job"].(map[string]any)["status"] != "queued" {
+		t.Fatalf("queued job: expected status queued, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodGet, "/api/v1/jobs/"+jobID, bobToken, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("other user's job: expected 404, got %d: %v", status, resp)
+	}
+
+	h.runJobs()
+
+	status, resp = h.do(http.MethodGet, "/api/v1/jobs/"+jobID, aliceToken, nil)
+	job := resp["job"].(map[string]any)
+	if status != http.StatusOK || job["status"] != "succeeded" || job["attempts"] != float64(1) {
+		t.Fatalf("finished job: expected one successful attempt, got %d: %v", status, resp)
+	}
+	plan := job["result"].(map[string]any)
+	if plan["dry_run"] != false || len(plan["accounts"].([]any)) != 1 {
+		t.Fatalf("finished job: expected the apply plan as the result, got %v", plan)
+	}
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("expected 1 application, got %d", got)
+	}
+}
+
+func TestVerifyJobReactivatesAccount(t *testing.T) {
+	h := newHarness(t)
+
+	aliceToken, _ := h.registerAndLogin("alice")
+	bobToken, _ := h.registerAndLogin("bob")
+	accountID := h.createAccount(aliceToken, "alice-meroshare")
+	h.db.Model(&models.Account{}).Where("id = ?", accountID).Update("status", "invalid_credentials")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/accounts/"+accountID+"/verify", bobToken, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("verify other user's account: expected 403, got %d: %v", status, resp)
+	}
+
+	status, resp = h.do(http.MethodPost, "/api/v1/accounts/"+accountID+"/verify", aliceToken, nil)
+	if status != http.StatusAccepted {
+		t.Fatalf("verify: expected 202, got %d: %v", status, resp)
+	}
+	h.runJobs()
+
+	var account models.Account
+	h.db.First(&account, "id = ?", accountID)
+	if account.Status != "active" {
+		t.Fatalf("expected verified account to be active, got %q", account.Status)
+	}
+}
+
+func TestSyncJobFillsIssueCalendar(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	status, resp := h.do(http.MethodPost, "/api/v1/issues/sync", token, nil)
+	if status != http.StatusAccepted {
+		t.Fatalf("sync: expected 202, got %d: %v", status, resp)
+	}
+	jobID := resp["job"].(map[string]any)["id"].(string)
+	h.runJobs()
+
+	_, resp = h.do(http.MethodGet, "/api/v1/jobs/"+jobID, token, nil)
+	if job := resp["job"].(map[string]any); job["status"] != "succeeded" {
+		t.Fatalf("sync job: expected success, got %v", job)
+	}
+	_, resp = h.do(http.MethodGet, "/api/v1/issues", token, nil)
+	if issues := resp["issues"].([]any); len(issues) != 1 {
+		t.Fatalf("expected the synced issue in the calendar, got %v", issues)
+	}
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("sync applied %d times", got)
+	}
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestCDSCCallsAreLoggedRedactedUnderTheRequestCorrelationID(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	hooks := logs.Logger.Hooks
+	logs.Logger.ReplaceHooks(logrus.LevelHooks{})
+	t.Cleanup(func() { logs.Logger.ReplaceHooks(hooks) })
+	entries := test.NewLocal(logs.Logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/shares/apply?dry_run=false", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "req-alice-1")
+	rec := httptest.NewRecorder()
+	h.app.Router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("enqueue apply: expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "req-alice-1" {
+		t.Fatalf("expected the correlation ID to be echoed, got %q", got)
+	}
+	h.runJobs()
+
+	var applyCall *logrus.Entry
+	for _, entry := range entries.AllEntries() {
+		data, _ := json.Marshal(entry.Data)
+		for _, secret := range []string{"meroshare-password", "CRN0001", `"1234"`} {
+			if strings.Contains(string(data), secret) {
+				t.Fatalf("log entry %q leaks %s: %s", entry.Message, secret, data)
+			}
+		}
+		if strings.HasPrefix(entry.Message, "CDSC request") && entry.Data["endpoint"] == "POST /applicantForm/share/apply/" {
+			applyCall = entry
+		}
+	}
+	if applyCall == nil {
+		t.Fatal("the share application was not logged")
+	}
+	if applyCall.Data["correlation_id"] != "req-alice-1" || applyCall.Data["status"] != http.StatusCreated {
+		t.Fatalf("unexpected log entry for the share application: %v", applyCall.Data)
+	}
+	if body := applyCall.Data["request_body"].(string); !strings.Contains(body, `"transactionPIN":"[REDACTED]"`) {
+		t.Fatalf("expected the PIN to be masked in the logged body, got %s", body)
+	}
+	if headers := applyCall.Data["request_headers"].(map[string]string); headers["Authorization"] != "[REDACTED]" {
+		t.Fatalf("expected the session token to be masked, got %v", headers)
+	}
+}
@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+	h.app.ApplyService.Run(context.Background())
+
+	rec := httptest.NewRecorder()
+	h.app.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected metrics to be kept off the API router, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.app.OpsRouter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`meroshare_http_request_duration_seconds_count{method="POST",route="/api/v1/login",status="200"} 1`,
+		`meroshare_cdsc_requests_total{endpoint="POST /auth/",error_class="none"}`,
+		`meroshare_cdsc_request_duration_seconds_count{endpoint="POST /applicantForm/share/apply/"} 1`,
+		`meroshare_apply_outcomes_total{status="applied"} 1`,
+		`meroshare_apply_run_duration_seconds_count 1`,
+		`meroshare_accounts{status="active"} 1`,
+		`go_sql_open_connections{db_name="meroshare"}`,
+		`meroshare_redis_pool_connections`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %s", want)
+		}
+	}
+}
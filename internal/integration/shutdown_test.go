@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	pkgredis "github.com/asrma7/meroshare-bot/pkg/redis"
+)
+
+func TestCancelledRunAppliesNothing(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	h.createAccount(token, "alice-meroshare")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	plan := h.app.ApplyService.Run(ctx)
+
+	for _, account := range plan.Accounts {
+		if account.Status != "skipped" {
+			t.Fatalf("expected a cancelled run to skip every account, got %+v", plan.Accounts)
+		}
+	}
+	if got := len(h.meroshare.Applications()); got != 0 {
+		t.Fatalf("cancelled run applied %d times", got)
+	}
+}
+
+func TestRecoverInFlightApplication(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+
+	// Simulate a process that submitted the application and died before recording the result.
+	h.app.ApplyService.Run(context.Background())
+	h.db.Model(&models.AppliedShare{}).Where("account_id = ?", accountID).Update("status", "in_flight")
+
+	lock, err := pkgredis.AcquireLock(context.Background(), h.redis, "meroshare:apply:account:"+accountID, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to take account lock: %v", err)
+	}
+	if recovered, err := h.app.ApplyService.RecoverInFlight(context.Background()); err != nil || recovered != 0 {
+		t.Fatalf("expected a locked account's row to be left alone, got %d (%v)", recovered, err)
+	}
+	lock.Release(context.Background())
+
+	recovered, err := h.app.ApplyService.RecoverInFlight(context.Background())
+	if err != nil || recovered != 1 {
+		t.Fatalf("expected 1 recovered application, got %d (%v)", recovered, err)
+	}
+	var share models.AppliedShare
+	h.db.First(&share, "account_id = ?", accountID)
+	if share.Status != "failed" || share.Remark != services.InterruptedRemark || share.Permanent {
+		t.Fatalf("expected a retryable interrupted failure, got %+v", share)
+	}
+
+	// MeroShare already has the application, so the retry records it as applied without a second one.
+	h.app.ApplyService.Run(context.Background())
+	h.db.First(&share, "account_id = ?", accountID)
+	if share.Status != "applied" || share.Attempts != 2 {
+		t.Fatalf("expected the retry to settle the row as applied, got %+v", share)
+	}
+	if got := len(h.meroshare.Applications()); got != 1 {
+		t.Fatalf("expected 1 application, got %d", got)
+	}
+}
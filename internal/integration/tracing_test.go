@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestApplyRunIsTracedFromRequestToMeroShare(t *testing.T) {
+	h := newHarness(t)
+
+	token, _ := h.registerAndLogin("alice")
+	accountID := h.createAccount(token, "alice-meroshare")
+
+	before := len(spanRecorder.Ended())
+	status, resp := h.do(http.MethodPost, "/api/v1/shares/apply?dry_run=false", token, nil)
+	if status != http.StatusAccepted {
+		t.Fatalf("enqueue apply: expected 202, got %d: %v", status, resp)
+	}
+	h.runJobs()
+	spans := spanRecorder.Ended()[before:]
+
+	var request sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.SpanKind() == trace.SpanKindServer && attributeValue(span, "http.route") == "/api/v1/shares/apply" {
+			request = span
+		}
+	}
+	if request == nil {
+		t.Fatalf("no server span for the apply request among %d spans", len(spans))
+	}
+
+	scopes := map[string]int{}
+	var cdscCalls []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.SpanContext().TraceID() != request.SpanContext().TraceID() {
+			continue
+		}
+		scopes[span.InstrumentationScope().Name]++
+		if strings.HasPrefix(span.Name(), "CDSC ") {
+			cdscCalls = append(cdscCalls, span)
+		}
+		for _, kv := range span.Attributes() {
+			value := kv.Value.Emit()
+			if strings.Contains(value, "meroshare-password") || strings.Contains(value, "CRN0001") {
+				t.Fatalf("span %q leaks a secret in %s=%q", span.Name(), kv.Key, value)
+			}
+		}
+	}
+	if scopes["gorm.io/plugin/opentelemetry"] == 0 {
+		t.Fatalf("expected database spans in the request's trace, got scopes %v", scopes)
+	}
+	if scopes["github.com/redis/go-redis/extra/redisotel"] == 0 {
+		t.Fatalf("expected Redis spans in the request's trace, got scopes %v", scopes)
+	}
+	if len(cdscCalls) == 0 {
+		t.Fatalf("expected MeroShare calls in the request's trace, got scopes %v", scopes)
+	}
+	endpoints := map[string]bool{}
+	for _, span := range cdscCalls {
+		if got := attributeValue(span, "account.id"); got != accountID {
+			t.Fatalf("CDSC span %q has account.id %q, want %q", span.Name(), got, accountID)
+		}
+		endpoints[attributeValue(span, "cdsc.endpoint")] = true
+	}
+	if !endpoints["POST /applicantForm/share/apply/"] {
+		t.Fatalf("expected the share application among the traced MeroShare calls, got %v", endpoints)
+	}
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
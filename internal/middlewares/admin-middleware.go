@@ -0,0 +1,17 @@
+package middlewares
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware only lets admins through. It has to run after AuthMiddleware.
+func AdminMiddleware(adminHandler handlers.AdminHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !adminHandler.Authorize(c) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
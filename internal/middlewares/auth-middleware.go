@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -44,6 +45,7 @@ func AuthMiddleware(authHandler handlers.AuthHandler) gin.HandlerFunc {
 		}
 
 		c.Set("userID", claims.UserID.String())
+		c.Request = c.Request.WithContext(logs.WithUserID(c.Request.Context(), claims.UserID.String()))
 		c.Next()
 	}
 }
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const CorrelationIDHeader = "X-Request-ID"
+
+// validCorrelationID keeps caller-supplied IDs short and printable, since they end up in every log
+// line of the request.
+var validCorrelationID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// CorrelationMiddleware gives every request a correlation ID, reusing the caller's X-Request-ID when
+// it sends a sensible one, and returns it in the response so a user can quote it.
+func CorrelationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(CorrelationIDHeader)
+		if !validCorrelationID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Header(CorrelationIDHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("correlation_id", id))
+		c.Request = c.Request.WithContext(logs.WithCorrelationID(c.Request.Context(), id))
+		c.Next()
+	}
+}
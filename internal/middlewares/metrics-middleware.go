@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware records the latency and status of every request under its route pattern, so
+// /accounts/:id is one series rather than one per account.
+func MetricsMiddleware(m metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	GrantRoleOwner   = "owner"
+	GrantRoleManager = "manager"
+	GrantRoleViewer  = "viewer"
+
+	GrantStatusPending  = "pending"
+	GrantStatusAccepted = "accepted"
+)
+
+// AccountGrant gives another user access to an account. The user the account belongs to is always
+// its owner and has no grant; everyone else needs an accepted grant.
+type AccountGrant struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AccountID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_account_grants_account_user"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_account_grants_account_user;index"`
+	Role       string    `gorm:"type:varchar(20);not null"`
+	Status     string    `gorm:"type:varchar(20);not null;default:'pending'"`
+	InvitedBy  uuid.UUID `gorm:"type:uuid;not null"`
+	AcceptedAt *time.Time
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (u *AccountGrant) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.New()
+	return
+}
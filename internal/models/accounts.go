@@ -8,31 +8,41 @@ import (
 )
 
 type Account struct {
-	ID                 uuid.UUID      `gorm:"type:uuid;primaryKey"`
-	UserID             uuid.UUID      `gorm:"type:uuid;not null;index"`
-	Name               string         `gorm:"not null"`
-	Email              string         `gorm:"not null"`
-	Contact            string         `gorm:"not null"`
-	ClientID           uint16         `gorm:"not null"`
-	Username           string         `gorm:"uniqueIndex;not null;type:varchar(50)"`
-	Password           string         `gorm:"not null"`
-	BankID             string         `gorm:"not null"`
-	CRNNumber          string         `gorm:"not null"`
-	TransactionPIN     string         `gorm:"not null"`
-	AccountTypeId      uint8          `gorm:"not null"`
-	PreferredKitta     string         `gorm:"not null"`
-	Demat              string         `gorm:"not null"`
-	BOID               string         `gorm:"not null"`
-	AccountNumber      string         `gorm:"not null"`
-	CustomerId         uint32         `gorm:"not null"`
-	AccountBranchId    uint32         `gorm:"not null"`
-	DMATExpiryDate     string         `gorm:"type:varchar(50);not null"`
-	ExpiredDate        time.Time      `gorm:"type:timestamptz;not null"`
-	PasswordExpiryDate time.Time      `gorm:"type:timestamptz;not null"`
-	CreatedAt          time.Time      `gorm:"type:timestamptz;default:now()"`
-	UpdatedAt          time.Time      `gorm:"type:timestamptz;default:now()"`
-	Status             string         `gorm:"type:varchar(20);default:'active'"`
-	DeletedAt          gorm.DeletedAt `gorm:"index"`
+	ID                  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID              uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name                string    `gorm:"not null"`
+	Email               string    `gorm:"not null"`
+	Contact             string    `gorm:"not null"`
+	ClientID            uint16    `gorm:"not null"`
+	Username            string    `gorm:"uniqueIndex:idx_accounts_username,where:deleted_at IS NULL;not null;type:varchar(50)"`
+	Password            string    `gorm:"not null"`
+	BankID              string    `gorm:"not null"`
+	CRNNumber           string    `gorm:"not null"`
+	TransactionPIN      string    `gorm:"not null"`
+	AccountTypeId       uint8     `gorm:"not null"`
+	PreferredKitta      string    `gorm:"not null"`
+	RightShareFraction  float64   `gorm:"default:0"`
+	ApplyMutualFunds    bool      `gorm:"default:false"`
+	MutualFundUnits     uint32    `gorm:"default:0"`
+	MutualFundMaxAmount float64   `gorm:"default:0"`
+	ApplyDebentures     bool      `gorm:"default:false"`
+	DebentureUnits      uint32    `gorm:"default:0"`
+	DebentureMaxAmount  float64   `gorm:"default:0"`
+	District            string    `gorm:"type:varchar(50);default:''"`
+	ForeignEmployment   bool      `gorm:"default:false"`
+	AvailableBalance    *float64
+	Demat               string         `gorm:"not null"`
+	BOID                string         `gorm:"not null"`
+	AccountNumber       string         `gorm:"not null"`
+	CustomerId          uint32         `gorm:"not null"`
+	AccountBranchId     uint32         `gorm:"not null"`
+	DMATExpiryDate      string         `gorm:"type:varchar(50);not null"`
+	ExpiredDate         time.Time      `gorm:"not null"`
+	PasswordExpiryDate  time.Time      `gorm:"not null"`
+	CreatedAt           time.Time      `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt           time.Time      `gorm:"default:CURRENT_TIMESTAMP"`
+	Status              string         `gorm:"type:varchar(20);default:'active'"`
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 }
 
 func (u *Account) BeforeCreate(tx *gorm.DB) (err error) {
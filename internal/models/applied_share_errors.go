@@ -13,9 +13,11 @@ type AppliedShareError struct {
 	AccountID      uuid.UUID `gorm:"type:uuid;not null;index"`
 	AppliedShareID uuid.UUID `gorm:"type:uuid;not null;index"`
 	Message        string    `gorm:"not null"`
+	Attempt        int       `gorm:"default:1"`
+	Permanent      bool      `gorm:"default:false"`
 	Seen           bool      `gorm:"default:false"`
-	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()"`
-	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 func (u *AppliedShareError) BeforeCreate(tx *gorm.DB) (err error) {
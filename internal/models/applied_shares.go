@@ -10,17 +10,24 @@ import (
 type AppliedShare struct {
 	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
 	UserID         uuid.UUID `gorm:"type:uuid;not null;index"`
-	AccountID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	AccountID      uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_applied_shares_account_share"`
 	CompanyName    string    `gorm:"not null"`
-	CompanyShareID uint16    `gorm:"not null"`
+	CompanyShareID uint16    `gorm:"not null;uniqueIndex:idx_applied_shares_account_share"`
 	Scrip          string    `gorm:"not null"`
 	AppliedKitta   string    `gorm:"not null"`
+	KittaSource    string    `gorm:"type:varchar(30);default:'preferred'"`
+	EligibleKitta  int       `gorm:"default:0"`
+	PricePerUnit   float64   `gorm:"default:0"`
+	Amount         float64   `gorm:"default:0"`
 	ShareGroupName string    `gorm:"not null"`
 	ShareTypeName  string    `gorm:"not null"`
 	SubGroup       string    `gorm:"not null"`
-	Status         string    `gorm:"type:varchar(20);default:'applied'"`
-	CreatedAt      time.Time `gorm:"type:timestamptz;default:now()"`
-	UpdatedAt      time.Time `gorm:"type:timestamptz;default:now()"`
+	Status         string    `gorm:"type:varchar(30);default:'applied'"`
+	Remark         string    `gorm:"default:''"`
+	Attempts       int       `gorm:"default:1"`
+	Permanent      bool      `gorm:"default:false"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 func (u *AppliedShare) BeforeCreate(tx *gorm.DB) (err error) {
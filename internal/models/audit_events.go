@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+// AuditEvent records a security-relevant action. Rows are never updated or deleted; the database
+// rejects both. UserID is nil when the actor could not be identified, e.g. a login attempt for an
+// unknown username.
+type AuditEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index"`
+	Action        string     `gorm:"type:varchar(50);not null;index"`
+	TargetType    string     `gorm:"type:varchar(50)"`
+	TargetID      string
+	Outcome       string `gorm:"type:varchar(20);not null"`
+	Detail        string
+	IPAddress     string `gorm:"type:varchar(45)"`
+	UserAgent     string
+	CorrelationID string
+	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP;index"`
+}
+
+func (u *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.New()
+	return
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Issue struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CompanyShareID uint16    `gorm:"uniqueIndex;not null"`
+	CompanyName    string    `gorm:"not null"`
+	Scrip          string    `gorm:"not null;index"`
+	ShareTypeName  string    `gorm:"not null"`
+	ShareGroupName string    `gorm:"not null"`
+	SubGroup       string    `gorm:"not null"`
+	IssueOpenDate  *time.Time
+	IssueCloseDate *time.Time `gorm:"index"`
+	Status         string     `gorm:"type:varchar(20);default:'open';index"`
+	FirstSeenAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+	LastSeenAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (u *Issue) BeforeCreate(tx *gorm.DB) (err error) {
+	u.ID = uuid.New()
+	return
+}
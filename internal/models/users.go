@@ -7,15 +7,24 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// User is someone who signs in to the bot. A disabled user can neither sign in nor use tokens issued
+// before they were disabled.
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
-	Username  string    `gorm:"uniqueIndex;not null;type:varchar(50)"`
-	Email     string    `gorm:"uniqueIndex;not null"`
-	Password  string    `gorm:"not null"`
-	FirstName string    `gorm:"not null;type:varchar(100)"`
-	LastName  string    `gorm:"not null;type:varchar(100)"`
-	CreatedAt time.Time `gorm:"type:timestamptz;default:now()"`
-	UpdatedAt time.Time `gorm:"type:timestamptz;default:now()"`
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Username   string    `gorm:"uniqueIndex;not null;type:varchar(50)"`
+	Email      string    `gorm:"uniqueIndex;not null"`
+	Password   string    `gorm:"not null"`
+	FirstName  string    `gorm:"not null;type:varchar(100)"`
+	LastName   string    `gorm:"not null;type:varchar(100)"`
+	Role       string    `gorm:"type:varchar(20);not null;default:'user'"`
+	DisabledAt *time.Time
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
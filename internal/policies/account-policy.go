@@ -0,0 +1,152 @@
+package policies
+
+import (
+	"context"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Action string
+
+const (
+	ActionView            Action = "view"
+	ActionViewCredentials Action = "view_credentials"
+	ActionApply           Action = "apply"
+	ActionUpdate          Action = "update"
+	ActionDelete          Action = "delete"
+	ActionManageGrants    Action = "manage_grants"
+)
+
+var roleRank = map[string]int{
+	models.GrantRoleViewer:  1,
+	models.GrantRoleManager: 2,
+	models.GrantRoleOwner:   3,
+}
+
+// minimumRole is the least role that may take each action on an account.
+var minimumRole = map[Action]string{
+	ActionView:            models.GrantRoleViewer,
+	ActionViewCredentials: models.GrantRoleManager,
+	ActionApply:           models.GrantRoleManager,
+	ActionUpdate:          models.GrantRoleManager,
+	ActionDelete:          models.GrantRoleOwner,
+	ActionManageGrants:    models.GrantRoleOwner,
+}
+
+// Allows reports whether role may take action. An empty or unknown role allows nothing.
+func Allows(role string, action Action) bool {
+	rank, ok := roleRank[role]
+	return ok && rank >= roleRank[minimumRole[action]]
+}
+
+// ValidRole reports whether role is one of the grant roles.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// AccountPolicy decides what a user may do with an account. The user an account belongs to is its
+// owner; anyone else gets the role of their accepted grant, if they have one.
+type AccountPolicy interface {
+	// Authorize loads a live account and checks that the user may take action on it.
+	Authorize(ctx context.Context, userID, accountID uuid.UUID, action Action) (*models.Account, string, error)
+	// Check checks an account that is already loaded, such as a deleted one being restored.
+	Check(ctx context.Context, userID uuid.UUID, account *models.Account, action Action) (string, error)
+	Role(ctx context.Context, userID uuid.UUID, account *models.Account) (string, error)
+	// Accounts lists the live accounts the user may take action on, with the user's role on each.
+	Accounts(ctx context.Context, userID uuid.UUID, action Action) ([]models.Account, map[uuid.UUID]string, error)
+}
+
+type accountPolicy struct {
+	accountRepo repositories.AccountRepository
+	grantRepo   repositories.GrantRepository
+}
+
+func NewAccountPolicy(accountRepo *repositories.AccountRepository, grantRepo *repositories.GrantRepository) AccountPolicy {
+	return &accountPolicy{
+		accountRepo: *accountRepo,
+		grantRepo:   *grantRepo,
+	}
+}
+
+func (p *accountPolicy) Authorize(ctx context.Context, userID, accountID uuid.UUID, action Action) (*models.Account, string, error) {
+	account, err := p.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", errors.NewNotFoundError("Account not found")
+		}
+		return nil, "", errors.NewInternalError(err)
+	}
+	role, err := p.Check(ctx, userID, account, action)
+	if err != nil {
+		return nil, "", err
+	}
+	return account, role, nil
+}
+
+func (p *accountPolicy) Check(ctx context.Context, userID uuid.UUID, account *models.Account, action Action) (string, error) {
+	role, err := p.Role(ctx, userID, account)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", errors.NewForbiddenError("You do not have access to this account")
+	}
+	if !Allows(role, action) {
+		return role, errors.NewForbiddenError("Your role on this account does not allow this")
+	}
+	return role, nil
+}
+
+func (p *accountPolicy) Role(ctx context.Context, userID uuid.UUID, account *models.Account) (string, error) {
+	if account.UserID == userID {
+		return models.GrantRoleOwner, nil
+	}
+	grant, err := p.grantRepo.GetGrant(ctx, account.ID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", nil
+		}
+		return "", errors.NewInternalError(err)
+	}
+	if grant.Status != models.GrantStatusAccepted {
+		return "", nil
+	}
+	return grant.Role, nil
+}
+
+func (p *accountPolicy) Accounts(ctx context.Context, userID uuid.UUID, action Action) ([]models.Account, map[uuid.UUID]string, error) {
+	accounts, err := p.accountRepo.GetAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	roles := make(map[uuid.UUID]string, len(accounts))
+	for _, account := range accounts {
+		roles[account.ID] = models.GrantRoleOwner
+	}
+
+	grants, err := p.grantRepo.GetAcceptedGrantsByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	grantedRoles := make(map[uuid.UUID]string, len(grants))
+	var granted []uuid.UUID
+	for _, grant := range grants {
+		if Allows(grant.Role, action) {
+			granted = append(granted, grant.AccountID)
+			grantedRoles[grant.AccountID] = grant.Role
+		}
+	}
+	shared, err := p.accountRepo.GetAccountsByIDs(ctx, granted)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	for _, account := range shared {
+		roles[account.ID] = grantedRoles[account.ID]
+	}
+	return append(accounts, shared...), roles, nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/asrma7/meroshare-bot/internal/models"
@@ -9,13 +10,19 @@ import (
 )
 
 type AccountRepository interface {
-	CreateAccount(account *models.Account) (uuid.UUID, error)
-	GetAccountByID(id uuid.UUID) (*models.Account, error)
-	GetAccountsByUserID(userID uuid.UUID) ([]models.Account, error)
-	GetAllAccounts() ([]models.Account, error)
-	UpdateAccount(account *models.Account) error
-	DeleteAccount(id uuid.UUID) error
-	SetAccountStatus(id uuid.UUID, status string) error
+	CreateAccount(ctx context.Context, account *models.Account) (uuid.UUID, error)
+	GetAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error)
+	GetAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Account, error)
+	GetAccountsByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Account, error)
+	GetAllAccounts(ctx context.Context) ([]models.Account, error)
+	UpdateAccount(ctx context.Context, account *models.Account) error
+	DeleteAccount(ctx context.Context, id uuid.UUID) error
+	GetDeletedAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error)
+	UsernameInUse(ctx context.Context, username string) (bool, error)
+	RestoreAccount(ctx context.Context, id uuid.UUID) error
+	PurgeAccountsDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	SetAccountStatus(ctx context.Context, id uuid.UUID, status string) error
+	CountAccountsByStatus(ctx context.Context) (map[string]int64, error)
 }
 
 type accountRepository struct {
@@ -26,52 +33,121 @@ func NewAccountRepository(db *gorm.DB) AccountRepository {
 	return &accountRepository{db: db}
 }
 
-func (r *accountRepository) CreateAccount(account *models.Account) (uuid.UUID, error) {
-	if err := r.db.Create(account).Error; err != nil {
+func (r *accountRepository) CreateAccount(ctx context.Context, account *models.Account) (uuid.UUID, error) {
+	if err := r.db.WithContext(ctx).Create(account).Error; err != nil {
 		return uuid.Nil, err
 	}
 	return account.ID, nil
 }
 
-func (r *accountRepository) GetAccountByID(id uuid.UUID) (*models.Account, error) {
+func (r *accountRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error) {
 	var account models.Account
-	if err := r.db.Where("id = ?", id).Where("deleted_at IS NULL").First(&account).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Where("deleted_at IS NULL").First(&account).Error; err != nil {
 		return nil, err
 	}
 	return &account, nil
 }
 
-func (r *accountRepository) GetAccountsByUserID(userID uuid.UUID) ([]models.Account, error) {
+func (r *accountRepository) GetAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Account, error) {
 	var accounts []models.Account
-	if err := r.db.Where("user_id = ?", userID).Where("deleted_at IS NULL").Find(&accounts).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Where("deleted_at IS NULL").Find(&accounts).Error; err != nil {
 		return nil, err
 	}
 	return accounts, nil
 }
 
-func (r *accountRepository) GetAllAccounts() ([]models.Account, error) {
+func (r *accountRepository) GetAccountsByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Account, error) {
 	var accounts []models.Account
-	if err := r.db.Where("deleted_at IS NULL").Find(&accounts).Error; err != nil {
+	if len(ids) == 0 {
+		return accounts, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Where("deleted_at IS NULL").Order("created_at ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// GetAllAccounts returns the accounts scheduled runs work through, leaving out those whose owner
+// has been disabled.
+func (r *accountRepository) GetAllAccounts(ctx context.Context) ([]models.Account, error) {
+	var accounts []models.Account
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		Where("user_id IN (?)", r.db.Model(&models.User{}).Select("id").Where("disabled_at IS NULL")).
+		Find(&accounts).Error
+	if err != nil {
 		return nil, err
 	}
 	return accounts, nil
 }
 
-func (r *accountRepository) UpdateAccount(account *models.Account) error {
-	if err := r.db.Save(account).Error; err != nil {
+func (r *accountRepository) UpdateAccount(ctx context.Context, account *models.Account) error {
+	if err := r.db.WithContext(ctx).Save(account).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *accountRepository) DeleteAccount(id uuid.UUID) error {
+func (r *accountRepository) DeleteAccount(ctx context.Context, id uuid.UUID) error {
 	// Soft delete: set DeletedAt to current time
-	if err := r.db.Model(&models.Account{}).Where("id = ?", id).Update("deleted_at", gorm.DeletedAt{Time: time.Now(), Valid: true}).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&models.Account{}).Where("id = ?", id).Update("deleted_at", gorm.DeletedAt{Time: time.Now(), Valid: true}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *accountRepository) SetAccountStatus(id uuid.UUID, status string) error {
-	return r.db.Model(&models.Account{}).Where("id = ?", id).Update("status", status).Error
+func (r *accountRepository) GetDeletedAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).Where("deleted_at IS NOT NULL").First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *accountRepository) UsernameInUse(ctx context.Context, username string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Account{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *accountRepository) RestoreAccount(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Account{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// PurgeAccountsDeletedBefore removes accounts soft-deleted before the given time for good, together
+// with the MeroShare credentials stored on them and the access granted to other users.
+func (r *accountRepository) PurgeAccountsDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&models.Account{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		if err := tx.Where("account_id IN (?)", expired).Delete(&models.AccountGrant{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.Account{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *accountRepository) SetAccountStatus(ctx context.Context, id uuid.UUID, status string) error {
+	return r.db.WithContext(ctx).Model(&models.Account{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *accountRepository) CountAccountsByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Account{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
 }
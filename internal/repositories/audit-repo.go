@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditRepository is append-only: events can be added and read, never changed.
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error
+	GetAuditEventsByUserID(ctx context.Context, userID uuid.UUID, action string, before *time.Time, limit int) ([]models.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	// SQLite compares timestamps as text, so events are stored in UTC like every other time.
+	event.CreatedAt = time.Now().UTC()
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// GetAuditEventsByUserID returns the user's most recent events first, optionally only those of one
+// action or from before a point in time.
+func (r *auditRepository) GetAuditEventsByUserID(ctx context.Context, userID uuid.UUID, action string, before *time.Time, limit int) ([]models.AuditEvent, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if before != nil {
+		query = query.Where("created_at < ?", before.UTC())
+	}
+	var events []models.AuditEvent
+	err := query.Order("created_at DESC").Order("id").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GrantRepository interface {
+	CreateGrant(ctx context.Context, grant *models.AccountGrant) error
+	GetGrantByID(ctx context.Context, id uuid.UUID) (*models.AccountGrant, error)
+	GetGrant(ctx context.Context, accountID, userID uuid.UUID) (*models.AccountGrant, error)
+	GetGrantsByAccountID(ctx context.Context, accountID uuid.UUID) ([]models.AccountGrant, error)
+	GetGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]models.AccountGrant, error)
+	GetAcceptedGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]models.AccountGrant, error)
+	AcceptGrant(ctx context.Context, id uuid.UUID) error
+	DeleteGrant(ctx context.Context, id uuid.UUID) error
+}
+
+type grantRepository struct {
+	db *gorm.DB
+}
+
+func NewGrantRepository(db *gorm.DB) GrantRepository {
+	return &grantRepository{db: db}
+}
+
+func (r *grantRepository) CreateGrant(ctx context.Context, grant *models.AccountGrant) error {
+	return r.db.WithContext(ctx).Create(grant).Error
+}
+
+func (r *grantRepository) GetGrantByID(ctx context.Context, id uuid.UUID) (*models.AccountGrant, error) {
+	var grant models.AccountGrant
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *grantRepository) GetGrant(ctx context.Context, accountID, userID uuid.UUID) (*models.AccountGrant, error) {
+	var grant models.AccountGrant
+	if err := r.db.WithContext(ctx).Where("account_id = ? AND user_id = ?", accountID, userID).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *grantRepository) GetGrantsByAccountID(ctx context.Context, accountID uuid.UUID) ([]models.AccountGrant, error) {
+	var grants []models.AccountGrant
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at ASC").Find(&grants).Error
+	return grants, err
+}
+
+func (r *grantRepository) GetGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]models.AccountGrant, error) {
+	var grants []models.AccountGrant
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&grants).Error
+	return grants, err
+}
+
+func (r *grantRepository) GetAcceptedGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]models.AccountGrant, error) {
+	var grants []models.AccountGrant
+	err := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, models.GrantStatusAccepted).Find(&grants).Error
+	return grants, err
+}
+
+func (r *grantRepository) AcceptGrant(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.AccountGrant{}).Where("id = ?", id).Updates(map[string]any{
+		"status":      models.GrantStatusAccepted,
+		"accepted_at": time.Now(),
+	}).Error
+}
+
+func (r *grantRepository) DeleteGrant(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.AccountGrant{}).Error
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IssueRepository interface {
+	UpsertIssue(ctx context.Context, issue *models.Issue) error
+	GetIssues(ctx context.Context, filter requests.IssueFilter) ([]models.Issue, error)
+	UpdateIssueStatuses(ctx context.Context, now time.Time) error
+}
+
+type issueRepository struct {
+	db *gorm.DB
+}
+
+func NewIssueRepository(db *gorm.DB) IssueRepository {
+	return &issueRepository{db: db}
+}
+
+func (r *issueRepository) UpsertIssue(ctx context.Context, issue *models.Issue) error {
+	// SQLite compares timestamps as text, so every stored and queried time is kept in UTC.
+	issue.IssueOpenDate = utcPtr(issue.IssueOpenDate)
+	issue.IssueCloseDate = utcPtr(issue.IssueCloseDate)
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "company_share_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"company_name",
+			"scrip",
+			"share_type_name",
+			"share_group_name",
+			"sub_group",
+			"issue_open_date",
+			"issue_close_date",
+			"status",
+			"last_seen_at",
+			"updated_at",
+		}),
+	}).Create(issue).Error
+}
+
+func (r *issueRepository) GetIssues(ctx context.Context, filter requests.IssueFilter) ([]models.Issue, error) {
+	query := r.db.WithContext(ctx).Model(&models.Issue{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Scrip != "" {
+		query = query.Where("UPPER(scrip) = UPPER(?)", filter.Scrip)
+	}
+	if filter.ShareTypeName != "" {
+		query = query.Where("UPPER(share_type_name) = UPPER(?)", filter.ShareTypeName)
+	}
+	if filter.ShareGroupName != "" {
+		query = query.Where("UPPER(share_group_name) = UPPER(?)", filter.ShareGroupName)
+	}
+	if filter.From != "" {
+		if from, err := time.Parse("2006-01-02", filter.From); err == nil {
+			query = query.Where("issue_close_date >= ?", from.UTC())
+		}
+	}
+	if filter.To != "" {
+		if to, err := time.Parse("2006-01-02", filter.To); err == nil {
+			query = query.Where("issue_open_date < ?", to.AddDate(0, 0, 1).UTC())
+		}
+	}
+
+	var issues []models.Issue
+	err := query.Order("issue_open_date DESC").Find(&issues).Error
+	return issues, err
+}
+
+func (r *issueRepository) UpdateIssueStatuses(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Issue{}).
+			Where("status <> ? AND issue_close_date IS NOT NULL AND issue_close_date <= ?", "closed", now).
+			Update("status", "closed").Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Issue{}).
+			Where("status = ? AND (issue_open_date IS NULL OR issue_open_date <= ?)", "upcoming", now).
+			Where("issue_close_date IS NULL OR issue_close_date > ?", now).
+			Update("status", "open").Error
+	})
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -1,22 +1,28 @@
 package repositories
 
 import (
+	"context"
 	"github.com/asrma7/meroshare-bot/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ShareRepository interface {
-	AddAppliedShare(share *models.AppliedShare) (uuid.UUID, error)
-	AddApplyShareError(error *models.AppliedShareError) error
-	GetAppliedSharesByUserID(userID string) ([]models.AppliedShare, error)
-	GetAppliedShareByID(shareID string) (*models.AppliedShare, error)
-	GetAppliedShareByAccountIDAndCompanyShareID(accountID string, companyShareID string) (*models.AppliedShare, error)
-	GetAppliedShareErrorsByUserID(userID string) ([]models.AppliedShareError, error)
-	GetAppliedShareErrorsByAppliedShareID(appliedShareID string) (*models.AppliedShareError, error)
-	MarkShareErrorsAsSeenByUserID(userID string) error
-	DeleteAllAppliedSharesByUserID(userID uuid.UUID) error
-	DeleteAllAppliedShareErrorsByUserID(userID uuid.UUID) error
+	AddAppliedShare(ctx context.Context, share *models.AppliedShare) (uuid.UUID, error)
+	AddApplyShareError(ctx context.Context, error *models.AppliedShareError) error
+	GetAppliedSharesByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShare, error)
+	GetAppliedShareByID(ctx context.Context, shareID string) (*models.AppliedShare, error)
+	GetAppliedShareByAccountIDAndCompanyShareID(ctx context.Context, accountID string, companyShareID string) (*models.AppliedShare, error)
+	GetAppliedSharesByStatus(ctx context.Context, status string) ([]models.AppliedShare, error)
+	GetAppliedShareErrorsByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShareError, error)
+	GetAppliedShareErrorsByAppliedShareID(ctx context.Context, appliedShareID string) ([]models.AppliedShareError, error)
+	UpdateAppliedShare(ctx context.Context, share *models.AppliedShare) error
+	InsertAppliedShareIfAbsent(ctx context.Context, share *models.AppliedShare) (bool, error)
+	ClaimAppliedShare(ctx context.Context, share *models.AppliedShare, previousStatus string, previousAttempts int) (bool, error)
+	MarkShareErrorsAsSeenByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) error
+	DeleteAllAppliedSharesByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteAllAppliedShareErrorsByUserID(ctx context.Context, userID uuid.UUID) error
 }
 
 type shareRepository struct {
@@ -27,64 +33,102 @@ func NewShareRepository(db *gorm.DB) ShareRepository {
 	return &shareRepository{db: db}
 }
 
-func (s *shareRepository) AddAppliedShare(share *models.AppliedShare) (uuid.UUID, error) {
-	if err := s.db.Create(share).Error; err != nil {
+func (s *shareRepository) AddAppliedShare(ctx context.Context, share *models.AppliedShare) (uuid.UUID, error) {
+	if err := s.db.WithContext(ctx).Create(share).Error; err != nil {
 		return uuid.Nil, err
 	}
 	return share.ID, nil
 }
 
-func (s *shareRepository) AddApplyShareError(error *models.AppliedShareError) error {
-	return s.db.Create(error).Error
+func (s *shareRepository) AddApplyShareError(ctx context.Context, error *models.AppliedShareError) error {
+	return s.db.WithContext(ctx).Create(error).Error
 }
 
-func (s *shareRepository) GetAppliedSharesByUserID(userID string) ([]models.AppliedShare, error) {
+// GetAppliedSharesByUserID returns the shares applied for on the user's own accounts, and on
+// accountIDs, which the user has been given access to.
+func (s *shareRepository) GetAppliedSharesByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShare, error) {
 	var shares []models.AppliedShare
-	err := s.db.Where("user_id = ?", userID).Find(&shares).Error
+	err := s.db.WithContext(ctx).Where(byUserOrAccounts(s.db, userID, accountIDs)).Find(&shares).Error
 	return shares, err
 }
 
-func (s *shareRepository) GetAppliedShareByID(shareID string) (*models.AppliedShare, error) {
+func (s *shareRepository) GetAppliedShareByID(ctx context.Context, shareID string) (*models.AppliedShare, error) {
 	var share models.AppliedShare
-	err := s.db.Where("id = ?", shareID).First(&share).Error
+	err := s.db.WithContext(ctx).Where("id = ?", shareID).First(&share).Error
 	if err != nil {
 		return nil, err
 	}
 	return &share, nil
 }
 
-func (s *shareRepository) GetAppliedShareByAccountIDAndCompanyShareID(accountID string, companyShareID string) (*models.AppliedShare, error) {
+func (s *shareRepository) GetAppliedShareByAccountIDAndCompanyShareID(ctx context.Context, accountID string, companyShareID string) (*models.AppliedShare, error) {
 	var share models.AppliedShare
-	err := s.db.Where("account_id = ? AND company_share_id = ?", accountID, companyShareID).First(&share).Error
+	err := s.db.WithContext(ctx).Where("account_id = ? AND company_share_id = ?", accountID, companyShareID).First(&share).Error
 	if err != nil {
 		return nil, err
 	}
 	return &share, nil
 }
 
-func (s *shareRepository) GetAppliedShareErrorsByUserID(userID string) ([]models.AppliedShareError, error) {
+func (s *shareRepository) GetAppliedSharesByStatus(ctx context.Context, status string) ([]models.AppliedShare, error) {
+	var shares []models.AppliedShare
+	err := s.db.WithContext(ctx).Where("status = ?", status).Order("updated_at ASC").Find(&shares).Error
+	return shares, err
+}
+
+func (s *shareRepository) GetAppliedShareErrorsByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShareError, error) {
 	var errors []models.AppliedShareError
-	err := s.db.Where("user_id = ?", userID).Find(&errors).Error
+	err := s.db.WithContext(ctx).Where(byUserOrAccounts(s.db, userID, accountIDs)).Find(&errors).Error
 	return errors, err
 }
 
-func (s *shareRepository) GetAppliedShareErrorsByAppliedShareID(appliedShareID string) (*models.AppliedShareError, error) {
-	var error models.AppliedShareError
-	err := s.db.Where("applied_share_id = ?", appliedShareID).First(&error).Error
-	if err != nil {
-		return nil, err
-	}
-	return &error, nil
+func (s *shareRepository) GetAppliedShareErrorsByAppliedShareID(ctx context.Context, appliedShareID string) ([]models.AppliedShareError, error) {
+	var errors []models.AppliedShareError
+	err := s.db.WithContext(ctx).Where("applied_share_id = ?", appliedShareID).Order("attempt ASC, created_at ASC").Find(&errors).Error
+	return errors, err
 }
 
-func (s *shareRepository) MarkShareErrorsAsSeenByUserID(userID string) error {
-	return s.db.Model(&models.AppliedShareError{}).Where("user_id = ?", userID).Update("seen", true).Error
+func (s *shareRepository) UpdateAppliedShare(ctx context.Context, share *models.AppliedShare) error {
+	return s.db.WithContext(ctx).Save(share).Error
+}
+
+// InsertAppliedShareIfAbsent inserts the row unless the account already has one for the issue, and
+// reports whether it was inserted.
+func (s *shareRepository) InsertAppliedShareIfAbsent(ctx context.Context, share *models.AppliedShare) (bool, error) {
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(share)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClaimAppliedShare overwrites an existing row only if it still has the status and attempt count
+// the caller read, so two runs cannot both take over the same retry.
+func (s *shareRepository) ClaimAppliedShare(ctx context.Context, share *models.AppliedShare, previousStatus string, previousAttempts int) (bool, error) {
+	result := s.db.WithContext(ctx).Model(share).
+		Where("status = ? AND attempts = ?", previousStatus, previousAttempts).
+		Select("*").Omit("id", "created_at").
+		Updates(share)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (s *shareRepository) MarkShareErrorsAsSeenByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) error {
+	return s.db.WithContext(ctx).Model(&models.AppliedShareError{}).Where(byUserOrAccounts(s.db, userID, accountIDs)).Update("seen", true).Error
+}
+
+func byUserOrAccounts(db *gorm.DB, userID string, accountIDs []uuid.UUID) *gorm.DB {
+	condition := db.Where("user_id = ?", userID)
+	if len(accountIDs) > 0 {
+		condition = condition.Or("account_id IN ?", accountIDs)
+	}
+	return condition
 }
 
-func (s *shareRepository) DeleteAllAppliedSharesByUserID(userID uuid.UUID) error {
-	return s.db.Where("user_id = ?", userID).Delete(&models.AppliedShare{}).Error
+// DeleteAllAppliedSharesByUserID clears the user's application history except in-flight and applied
+// rows, which are what stops a later run from submitting the same application again.
+func (s *shareRepository) DeleteAllAppliedSharesByUserID(ctx context.Context, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).
+		Where("user_id = ? AND status NOT IN ?", userID, []string{"in_flight", "applied"}).
+		Delete(&models.AppliedShare{}).Error
 }
 
-func (s *shareRepository) DeleteAllAppliedShareErrorsByUserID(userID uuid.UUID) error {
-	return s.db.Where("user_id = ?", userID).Delete(&models.AppliedShareError{}).Error
+func (s *shareRepository) DeleteAllAppliedShareErrorsByUserID(ctx context.Context, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.AppliedShareError{}).Error
 }
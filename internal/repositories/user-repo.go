@@ -1,17 +1,24 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/responses"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	CreateUser(user *models.User) (uuid.UUID, error)
-	GetUserByID(id uuid.UUID) (*models.User, error)
-	GetUserByUsername(username string) (*models.User, error)
-	GetUserByEmail(email string) (*models.User, error)
-	GetAllUsers() ([]models.User, error)
+	CreateUser(ctx context.Context, user *models.User) (uuid.UUID, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetAllUsers(ctx context.Context) ([]models.User, error)
+	SetUserRole(ctx context.Context, id uuid.UUID, role string) error
+	SetUserDisabledAt(ctx context.Context, id uuid.UUID, disabledAt *time.Time) error
+	GetUserDashboard(ctx context.Context, userID uuid.UUID) (responses.UserDashboard, error)
 }
 
 type userRepository struct {
@@ -22,41 +29,136 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) CreateUser(user *models.User) (uuid.UUID, error) {
-	if err := r.db.Create(user).Error; err != nil {
+func (r *userRepository) CreateUser(ctx context.Context, user *models.User) (uuid.UUID, error) {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
 		return uuid.Nil, err
 	}
 	return user.ID, nil
 }
 
-func (r *userRepository) GetUserByID(id uuid.UUID) (*models.User, error) {
+func (r *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetUserByUsername(username string) (*models.User, error) {
+func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, "username = ?", username).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, "username = ?", username).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetUserByEmail(email string) (*models.User, error) {
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, "email = ?", email).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, "email = ?", email).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetAllUsers() ([]models.User, error) {
+func (r *userRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Find(&users).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
+
+func (r *userRepository) SetUserRole(ctx context.Context, id uuid.UUID, role string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+func (r *userRepository) SetUserDisabledAt(ctx context.Context, id uuid.UUID, disabledAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("disabled_at", disabledAt).Error
+}
+
+func (r *userRepository) GetUserDashboard(ctx context.Context, userID uuid.UUID) (responses.UserDashboard, error) {
+	var resp responses.UserDashboard
+	var user models.User
+	if err := r.db.WithContext(ctx).Select("first_name", "last_name").
+		Where("id = ?", userID).
+		First(&user).Error; err != nil {
+		return responses.UserDashboard{}, err
+	}
+	resp.User = responses.UserSummary{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}
+
+	var totalAccounts int64
+	if err := r.db.WithContext(ctx).Model(&models.Account{}).
+		Where("user_id = ?", userID).
+		Count(&totalAccounts).Error; err != nil {
+		return responses.UserDashboard{}, err
+	}
+	resp.TotalAccounts = int(totalAccounts)
+
+	var accountsWithIssue int64
+	if err := r.db.WithContext(ctx).Model(&models.Account{}).
+		Where("user_id = ? AND status <> ?", userID, "active").
+		Count(&accountsWithIssue).Error; err != nil {
+		return responses.UserDashboard{}, err
+	}
+	resp.AccountsWithIssue = int(accountsWithIssue)
+
+	var totalShares int64
+	if err := r.db.WithContext(ctx).Model(&models.AppliedShare{}).
+		Where("user_id = ?", userID).
+		Count(&totalShares).Error; err != nil {
+		return responses.UserDashboard{}, err
+	}
+	resp.TotalShares = int(totalShares)
+
+	var failedShares int64
+	if err := r.db.WithContext(ctx).Model(&models.AppliedShare{}).
+		Where("user_id = ? AND status = ?", userID, "failed").
+		Count(&failedShares).Error; err != nil {
+		return responses.UserDashboard{}, err
+	}
+	resp.FailedShares = int(failedShares)
+
+	type failedRow struct {
+		AccountID   uuid.UUID
+		AccountName string
+		ShareID     uuid.UUID
+		Scrip       string
+		ErrorMsg    string
+		CreatedAt   time.Time
+	}
+
+	var rows []failedRow
+	if err := r.db.WithContext(ctx).Table("applied_share_errors ase").
+		Select(`
+			ase.account_id,
+			a.name AS account_name,
+			ase.applied_share_id AS share_id,
+			as_.scrip,
+			ase.message AS error_msg,
+			ase.created_at
+		`).
+		Joins("JOIN accounts a ON ase.account_id = a.id").
+		Joins("JOIN applied_shares as_ ON ase.applied_share_id = as_.id").
+		Where("ase.user_id = ?", userID).
+		Where("ase.seen = ?", false).
+		Order("ase.created_at DESC").
+		Scan(&rows).Error; err != nil {
+		return responses.UserDashboard{}, err
+	}
+
+	for _, row := range rows {
+		resp.FailedApplications = append(resp.FailedApplications, responses.FailedApplication{
+			AccountID:   row.AccountID.String(),
+			AccountName: row.AccountName,
+			ShareID:     row.ShareID.String(),
+			Scrip:       row.Scrip,
+			ErrorMsg:    row.ErrorMsg,
+			CreatedAt:   row.CreatedAt.Format(time.RFC3339Nano),
+		})
+	}
+
+	return resp, nil
+}
@@ -1,11 +1,21 @@
 package requests
 
 type AccountRequest struct {
-	ClientId       uint16 `json:"client_id" binding:"required"`
-	Username       string `json:"username" binding:"required"`
-	Password       string `json:"password" binding:"required"`
-	BankId         string `json:"bank_id" binding:"required"`
-	CRNNumber      string `json:"crn_number" binding:"required"`
-	TransactionPIN string `json:"transaction_pin" binding:"required"`
-	PreferredKitta uint16 `json:"preferred_kitta" binding:"required,min=10"`
+	ClientId            uint16   `json:"client_id" binding:"required"`
+	Username            string   `json:"username" binding:"required"`
+	Password            string   `json:"password" binding:"required"`
+	BankId              string   `json:"bank_id" binding:"required"`
+	CRNNumber           string   `json:"crn_number" binding:"required"`
+	TransactionPIN      string   `json:"transaction_pin" binding:"required"`
+	PreferredKitta      uint16   `json:"preferred_kitta" binding:"required,min=10"`
+	RightShareFraction  float64  `json:"right_share_fraction" binding:"omitempty,gt=0,lte=1"`
+	ApplyMutualFunds    bool     `json:"apply_mutual_funds"`
+	MutualFundUnits     uint32   `json:"mutual_fund_units"`
+	MutualFundMaxAmount float64  `json:"mutual_fund_max_amount" binding:"omitempty,gt=0"`
+	ApplyDebentures     bool     `json:"apply_debentures"`
+	DebentureUnits      uint32   `json:"debenture_units"`
+	DebentureMaxAmount  float64  `json:"debenture_max_amount" binding:"omitempty,gt=0"`
+	District            string   `json:"district" binding:"max=50"`
+	ForeignEmployment   bool     `json:"foreign_employment"`
+	AvailableBalance    *float64 `json:"available_balance" binding:"omitempty,gte=0"`
 }
@@ -0,0 +1,9 @@
+package requests
+
+type AdminRunRequest struct {
+	Type string `json:"type" binding:"required,oneof=apply sync"`
+}
+
+type CDSCErrorsFilter struct {
+	Window string `form:"window"`
+}
@@ -0,0 +1,7 @@
+package requests
+
+type AuditFilter struct {
+	Action string `form:"action"`
+	Before string `form:"before" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
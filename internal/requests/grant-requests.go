@@ -0,0 +1,7 @@
+package requests
+
+type GrantRequest struct {
+	// Identifier is the username or email address of the user to invite.
+	Identifier string `json:"identifier" binding:"required"`
+	Role       string `json:"role" binding:"required,oneof=owner manager viewer"`
+}
@@ -0,0 +1,10 @@
+package requests
+
+type IssueFilter struct {
+	Status         string `form:"status" binding:"omitempty,oneof=upcoming open closed"`
+	Scrip          string `form:"scrip"`
+	ShareTypeName  string `form:"share_type"`
+	ShareGroupName string `form:"share_group"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -27,3 +27,7 @@ type ApplyShareRequest struct {
 	CompanyShareID  string `json:"companyShareId"`
 	BankID          string `json:"bankId"`
 }
+
+type ApplyRunRequest struct {
+	DryRun *bool `form:"dry_run"`
+}
@@ -11,6 +11,11 @@ type BankDetails struct {
 	ID              uint32 `json:"id"`
 }
 
+type BankBalance struct {
+	AccountNumber    string  `json:"accountNumber"`
+	AvailableBalance float64 `json:"availableBalance"`
+}
+
 type UserDetails struct {
 	BOID               string    `json:"boid"`
 	Contact            string    `json:"contact"`
@@ -0,0 +1,32 @@
+package responses
+
+import "time"
+
+type ApplyPlan struct {
+	DryRun    bool          `json:"dry_run"`
+	StartedAt time.Time     `json:"started_at"`
+	Accounts  []AccountPlan `json:"accounts"`
+}
+
+type AccountPlan struct {
+	AccountID   string      `json:"account_id"`
+	AccountName string      `json:"account_name"`
+	Status      string      `json:"status"`
+	Reason      string      `json:"reason,omitempty"`
+	Balance     *float64    `json:"balance,omitempty"`
+	Issues      []IssuePlan `json:"issues"`
+}
+
+type IssuePlan struct {
+	CompanyShareID uint16  `json:"company_share_id"`
+	Scrip          string  `json:"scrip"`
+	CompanyName    string  `json:"company_name"`
+	ShareTypeName  string  `json:"share_type_name"`
+	ShareGroupName string  `json:"share_group_name"`
+	SubGroup       string  `json:"sub_group"`
+	Kitta          string  `json:"kitta,omitempty"`
+	KittaSource    string  `json:"kitta_source,omitempty"`
+	Amount         float64 `json:"amount,omitempty"`
+	Action         string  `json:"action"`
+	Reason         string  `json:"reason,omitempty"`
+}
@@ -0,0 +1,21 @@
+package responses
+
+import "time"
+
+// ReadinessReport is the result of every readiness check. The process is ready when all critical
+// checks pass; a failed non-critical check only marks it degraded.
+type ReadinessReport struct {
+	Ready     bool          `json:"ready"`
+	Degraded  bool          `json:"degraded"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []HealthCheck `json:"checks"`
+}
+
+type HealthCheck struct {
+	Name       string         `json:"name"`
+	Status     string         `json:"status"`
+	Critical   bool           `json:"critical"`
+	DurationMs int64          `json:"duration_ms"`
+	Error      string         `json:"error,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+}
@@ -17,3 +17,16 @@ type ApplicableSharesResponse struct {
 	Shares     []ApplicableShare `json:"object"`
 	TotalCount int               `json:"totalCount"`
 }
+
+type RightShareEligibility struct {
+	BOID          string `json:"boid"`
+	EligibleKitta int    `json:"eligibleKitta"`
+}
+
+type IssueDetails struct {
+	CompanyShareID uint16  `json:"companyShareId"`
+	MinUnit        int     `json:"minUnit"`
+	MaxUnit        int     `json:"maxUnit"`
+	MultipleOf     int     `json:"multipleOf"`
+	SharePerUnit   float64 `json:"sharePerUnit"`
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/asrma7/meroshare-bot/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAdminRoutes(r *gin.RouterGroup, adminHandler handlers.AdminHandler) {
+	admin := r.Group("/admin")
+	admin.Use(middlewares.AdminMiddleware(adminHandler))
+	admin.GET("/users", adminHandler.ListUsers)
+	admin.POST("/users/:id/disable", adminHandler.DisableUser)
+	admin.POST("/users/:id/enable", adminHandler.EnableUser)
+	admin.GET("/accounts/stats", adminHandler.GetAccountStats)
+	admin.POST("/runs", adminHandler.TriggerRun)
+	admin.GET("/jobs/:id", adminHandler.GetJob)
+	admin.GET("/cdsc/errors", adminHandler.GetCDSCErrors)
+}
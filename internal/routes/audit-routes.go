@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAuditRoutes(r *gin.RouterGroup, auditHandler handlers.AuditHandler) {
+	r.GET("/audit", auditHandler.GetAuditEvents)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterGrantRoutes(r *gin.RouterGroup, grantHandler handlers.GrantHandler) {
+	r.POST("/accounts/:id/grants", grantHandler.InviteUser)
+	r.GET("/accounts/:id/grants", grantHandler.GetAccountGrants)
+	r.DELETE("/accounts/:id/grants/:grantID", grantHandler.RevokeGrant)
+	r.GET("/grants", grantHandler.GetUserGrants)
+	r.POST("/grants/:id/accept", grantHandler.AcceptGrant)
+	r.DELETE("/grants/:id", grantHandler.LeaveGrant)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterHealthRoutes(r *gin.Engine, healthHandler handlers.HealthHandler) {
+	r.GET("/healthz", healthHandler.Health)
+	r.GET("/readyz", healthHandler.Ready)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterIssueRoutes(r *gin.RouterGroup, issueHandler handlers.IssueHandler) {
+	r.GET("/issues", issueHandler.GetIssues)
+	r.POST("/issues/sync", issueHandler.SyncIssues)
+}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterJobRoutes(r *gin.RouterGroup, jobHandler handlers.JobHandler) {
+	r.GET("/jobs/:id", jobHandler.GetJob)
+}
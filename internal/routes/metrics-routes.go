@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/asrma7/meroshare-bot/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterMetricsRoutes(r *gin.Engine, m metrics.Metrics) {
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+}
@@ -2,14 +2,25 @@ package routes
 
 import (
 	"github.com/asrma7/meroshare-bot/internal/handlers"
+	"github.com/asrma7/meroshare-bot/internal/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(router *gin.Engine, authHandler handlers.AuthHandler, userHandler handlers.UserHandler, accountHandler handlers.AccountHandler, shareHandler handlers.ShareHandler) {
+func RegisterRoutes(router *gin.Engine, authHandler handlers.AuthHandler, userHandler handlers.UserHandler, accountHandler handlers.AccountHandler, shareHandler handlers.ShareHandler, issueHandler handlers.IssueHandler, jobHandler handlers.JobHandler, auditHandler handlers.AuditHandler, grantHandler handlers.GrantHandler, adminHandler handlers.AdminHandler, healthHandler handlers.HealthHandler) {
+	RegisterHealthRoutes(router, healthHandler)
+
 	api := router.Group("/api/v1")
 
 	RegisterAuthRoutes(api, authHandler)
-	RegisterUserRoutes(api, authHandler, userHandler)
-	RegisterAccountRoutes(api, authHandler, accountHandler)
-	RegisterShareRoutes(api, authHandler, shareHandler)
+	// Everything else needs a signed-in user; the middleware is attached once, here.
+	authed := api.Group("")
+	authed.Use(middlewares.AuthMiddleware(authHandler))
+	RegisterUserRoutes(authed, userHandler)
+	RegisterAccountRoutes(authed, accountHandler)
+	RegisterShareRoutes(authed, shareHandler)
+	RegisterIssueRoutes(authed, issueHandler)
+	RegisterJobRoutes(authed, jobHandler)
+	RegisterAuditRoutes(authed, auditHandler)
+	RegisterGrantRoutes(authed, grantHandler)
+	RegisterAdminRoutes(authed, adminHandler)
 }
@@ -2,14 +2,13 @@ package routes
 
 import (
 	"github.com/asrma7/meroshare-bot/internal/handlers"
-	"github.com/asrma7/meroshare-bot/internal/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterShareRoutes(r *gin.RouterGroup, authHandler handlers.AuthHandler, shareHandler handlers.ShareHandler) {
-	r.Use(middlewares.AuthMiddleware(authHandler))
+func RegisterShareRoutes(r *gin.RouterGroup, shareHandler handlers.ShareHandler) {
 	r.GET("/shares/applied", shareHandler.GetAppliedShares)
 	r.GET("/shares/errors", shareHandler.GetAppliedShareErrors)
 	r.GET("/shares/:id", shareHandler.GetAppliedShareByID)
 	r.POST("/shares/errors/mark-seen", shareHandler.MarkShareErrorsAsSeenByUserID)
+	r.POST("/shares/apply", shareHandler.RunApply)
 }
@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/redis"
+	"github.com/asrma7/meroshare-bot/pkg/tracing"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+type Scheduler interface {
+	Start() error
+	Stop(ctx context.Context) error
+	SyncCloseDayRuns()
+}
+
+type scheduler struct {
+	cron           *cron.Cron
+	location       *time.Location
+	applySchedule  string
+	purgeSchedule  string
+	closeDayHour   int
+	closeDayMin    int
+	jobService     services.JobService
+	issueService   services.IssueService
+	accountService services.AccountService
+	healthService  services.HealthService
+	leader         redis.LeaderElector
+
+	mu           sync.Mutex
+	closeDayRuns map[int64]closeDayRun
+}
+
+// closeDayRun is an extra apply run on the last day of one or more issues, keyed by the Unix time it
+// runs at, so issues closing at the same time share a single run.
+type closeDayRun struct {
+	entryID  cron.EntryID
+	shareIDs []uint16
+}
+
+// NewScheduler builds the cron scheduler. Every worker replica runs one, but only the replica
+// holding the leader lease enqueues the scheduled apply jobs.
+func NewScheduler(cfg *config.Config, jobService services.JobService, issueService services.IssueService, accountService services.AccountService, healthService services.HealthService, leader redis.LeaderElector) Scheduler {
+	location := utils.NepalLocation()
+	hour, minute := 10, 0
+	if t, err := time.Parse("15:04", cfg.CloseDayRunTime); err == nil {
+		hour, minute = t.Hour(), t.Minute()
+	} else {
+		logs.Warn("Invalid close-day run time, using 10:00", map[string]any{"value": cfg.CloseDayRunTime})
+	}
+
+	return &scheduler{
+		cron:           cron.New(cron.WithLocation(location)),
+		location:       location,
+		applySchedule:  cfg.ApplySchedule,
+		purgeSchedule:  cfg.PurgeSchedule,
+		closeDayHour:   hour,
+		closeDayMin:    minute,
+		jobService:     jobService,
+		issueService:   issueService,
+		accountService: accountService,
+		healthService:  healthService,
+		leader:         leader,
+		closeDayRuns:   make(map[int64]closeDayRun),
+	}
+}
+
+func (s *scheduler) Start() error {
+	if _, err := s.cron.AddFunc(s.applySchedule, s.run); err != nil {
+		return fmt.Errorf("invalid apply schedule %q: %w", s.applySchedule, err)
+	}
+	// Apply jobs update the calendar after the scheduled run has returned, so pick up their
+	// closing dates periodically as well.
+	if _, err := s.cron.AddFunc("@every 30m", s.SyncCloseDayRuns); err != nil {
+		return err
+	}
+	if _, err := s.cron.AddFunc(s.purgeSchedule, s.purgeDeletedAccounts); err != nil {
+		return fmt.Errorf("invalid purge schedule %q: %w", s.purgeSchedule, err)
+	}
+	s.SyncCloseDayRuns()
+	s.leader.Start()
+	s.cron.Start()
+	return nil
+}
+
+// Stop stops scheduling runs and waits for running cron jobs to return, or for ctx to be done, before
+// handing back the leader lease.
+func (s *scheduler) Stop(ctx context.Context) error {
+	defer s.leader.Stop()
+	select {
+	case <-s.cron.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run enqueues an apply job for every account on the leader only, but every replica keeps its
+// close-day runs in sync so a follower that takes over leadership already has them scheduled.
+func (s *scheduler) run() {
+	if s.leader.IsLeader() {
+		// The scheduled run starts its own trace and correlation ID, which the worker continues.
+		ctx := logs.WithCorrelationID(context.Background(), uuid.NewString())
+		ctx, span := tracing.Tracer().Start(ctx, "scheduler.run")
+		job, err := s.jobService.Enqueue(ctx, services.JobTypeApply, "", nil)
+		tracing.RecordError(span, err)
+		span.End()
+		if err != nil {
+			logs.ErrorContext(ctx, "Failed to enqueue scheduled apply run", map[string]any{"error": err})
+		} else {
+			logs.InfoContext(ctx, "Enqueued scheduled apply run", map[string]any{"job_id": job.ID})
+			if err := s.healthService.RecordSchedulerRun(time.Now()); err != nil {
+				logs.Warn("Failed to record scheduled run", map[string]any{"error": err})
+			}
+		}
+	} else {
+		logs.Info("Skipping scheduled apply run, another replica is the leader", map[string]any{"leader": s.leader.Status().Leader})
+	}
+	s.SyncCloseDayRuns()
+}
+
+// purgeDeletedAccounts removes accounts whose restore window has passed. Only the leader runs it,
+// though running it on several replicas at once would do no harm.
+func (s *scheduler) purgeDeletedAccounts() {
+	if !s.leader.IsLeader() {
+		return
+	}
+	purged, err := s.accountService.PurgeDeletedAccounts(context.Background())
+	if err != nil {
+		logs.Error("Failed to purge deleted accounts", map[string]any{"error": err})
+		return
+	}
+	if purged > 0 {
+		logs.Info("Purged deleted accounts", map[string]any{"count": purged})
+	}
+}
+
+// SyncCloseDayRuns schedules a run on the closing day of every upcoming or open issue in the calendar,
+// so accounts that could not apply earlier get another chance, and drops runs no issue needs any more.
+// Each run applies for every account, so issues closing at the same time get one run between them.
+func (s *scheduler) SyncCloseDayRuns() {
+	wanted := make(map[int64][]uint16)
+	for _, status := range []string{"upcoming", "open"} {
+		issues, err := s.issueService.GetIssues(context.Background(), requests.IssueFilter{Status: status})
+		if err != nil {
+			logs.Error("Failed to load issues for close-day runs", map[string]any{"error": err})
+			return
+		}
+		for _, issue := range issues {
+			if issue.IssueCloseDate == nil {
+				continue
+			}
+			if at, ok := s.closeDayRunTime(*issue.IssueCloseDate); ok {
+				wanted[at.Unix()] = append(wanted[at.Unix()], issue.CompanyShareID)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, run := range s.closeDayRuns {
+		if _, ok := wanted[key]; !ok {
+			s.cron.Remove(run.entryID)
+			delete(s.closeDayRuns, key)
+		}
+	}
+
+	for key, shareIDs := range wanted {
+		if run, ok := s.closeDayRuns[key]; ok {
+			run.shareIDs = shareIDs
+			s.closeDayRuns[key] = run
+			continue
+		}
+		at := time.Unix(key, 0).In(s.location)
+		spec := fmt.Sprintf("%d %d %d %d *", at.Minute(), at.Hour(), at.Day(), int(at.Month()))
+		entryID, err := s.cron.AddFunc(spec, s.closeDayJob(key))
+		if err != nil {
+			logs.Error("Failed to schedule close-day run", map[string]any{"error": err, "share_ids": shareIDs})
+			continue
+		}
+		s.closeDayRuns[key] = closeDayRun{entryID: entryID, shareIDs: shareIDs}
+		logs.Info("Scheduled close-day run", map[string]any{"share_ids": shareIDs, "at": at})
+	}
+}
+
+func (s *scheduler) closeDayJob(key int64) func() {
+	return func() {
+		s.mu.Lock()
+		run, ok := s.closeDayRuns[key]
+		if ok {
+			s.cron.Remove(run.entryID)
+			delete(s.closeDayRuns, key)
+		}
+		s.mu.Unlock()
+
+		logs.Info("Running close-day apply", map[string]any{"share_ids": run.shareIDs})
+		s.run()
+	}
+}
+
+// closeDayRunTime picks the configured time on the closing day, or an hour before closing when the
+// issue closes earlier than that, and reports false when the moment has already passed.
+func (s *scheduler) closeDayRunTime(closesAt time.Time) (time.Time, bool) {
+	closesAt = closesAt.In(s.location)
+	at := time.Date(closesAt.Year(), closesAt.Month(), closesAt.Day(), s.closeDayHour, s.closeDayMin, 0, 0, s.location)
+	if !at.Before(closesAt) {
+		at = closesAt.Add(-time.Hour).Truncate(time.Minute)
+	}
+	return at, at.After(time.Now())
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
+	"github.com/robfig/cron/v3"
+)
+
+func TestMain(m *testing.M) {
+	logs.InitLogger()
+	logs.Logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+type stubIssueService struct {
+	open []models.Issue
+}
+
+func (s *stubIssueService) SyncIssues(ctx context.Context, shares []responses.ApplicableShare) error {
+	return nil
+}
+
+func (s *stubIssueService) RefreshIssueStatuses(ctx context.Context) error {
+	return nil
+}
+
+func (s *stubIssueService) GetIssues(ctx context.Context, filter requests.IssueFilter) ([]models.Issue, error) {
+	if filter.Status == "open" {
+		return s.open, nil
+	}
+	return nil, nil
+}
+
+func TestIssuesClosingTogetherShareOneCloseDayRun(t *testing.T) {
+	location := utils.NepalLocation()
+	tomorrow := time.Now().In(location).AddDate(0, 0, 1)
+	closesAt := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 17, 0, 0, 0, location)
+	closesEarly := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 9, 0, 0, 0, location)
+
+	issues := &stubIssueService{open: []models.Issue{
+		{CompanyShareID: 700, IssueCloseDate: &closesAt},
+		{CompanyShareID: 701, IssueCloseDate: &closesAt},
+		{CompanyShareID: 702, IssueCloseDate: &closesEarly},
+	}}
+	s := &scheduler{
+		cron:         cron.New(cron.WithLocation(location)),
+		location:     location,
+		closeDayHour: 10,
+		issueService: issues,
+		closeDayRuns: make(map[int64]closeDayRun),
+	}
+
+	s.SyncCloseDayRuns()
+	if got := len(s.cron.Entries()); got != 2 {
+		t.Fatalf("expected one run at 10:00 and one before the early close, got %d entries", got)
+	}
+	run := s.closeDayRuns[time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 10, 0, 0, 0, location).Unix()]
+	if len(run.shareIDs) != 2 {
+		t.Fatalf("expected the two issues closing at 17:00 to share the 10:00 run, got %v", run.shareIDs)
+	}
+
+	issues.open = issues.open[:1]
+	s.SyncCloseDayRuns()
+	if got := len(s.cron.Entries()); got != 1 {
+		t.Fatalf("expected the early run to be dropped with its issue, got %d entries", got)
+	}
+}
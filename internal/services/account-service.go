@@ -2,40 +2,59 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/asrma7/meroshare-bot/internal/models"
 	"github.com/asrma7/meroshare-bot/internal/repositories"
 	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AccountService interface {
-	LoginAccount(clientId uint16, username, password string) (string, error)
-	FetchUserDetails(authorization string) (responses.UserDetails, error)
-	FetchBankDetails(authorization string, bankId string) ([]responses.BankDetails, error)
-	CreateAccount(account *models.Account) (uuid.UUID, error)
-	GetAccountByID(id uuid.UUID) (*models.Account, error)
-	GetAccountsByUserID(userID uuid.UUID) ([]models.Account, error)
-	GetAllAccounts() ([]models.Account, error)
-	UpdateAccount(account *models.Account) error
-	DeleteAccount(id uuid.UUID) error
-	SetAccountStatus(id uuid.UUID, status string) error
+	LoginAccount(ctx context.Context, clientId uint16, username, password string) (string, error)
+	FetchUserDetails(ctx context.Context, authorization string) (responses.UserDetails, error)
+	FetchBankDetails(ctx context.Context, authorization string, bankId string) ([]responses.BankDetails, error)
+	FetchBankBalance(ctx context.Context, authorization string, account models.Account) (float64, bool, error)
+	CreateAccount(ctx context.Context, account *models.Account) (uuid.UUID, error)
+	GetAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error)
+	GetAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Account, error)
+	GetAllAccounts(ctx context.Context) ([]models.Account, error)
+	UpdateAccount(ctx context.Context, account *models.Account) error
+	DeleteAccount(ctx context.Context, id uuid.UUID) error
+	GetDeletedAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error)
+	RestoreAccount(ctx context.Context, account *models.Account) error
+	PurgeDeletedAccounts(ctx context.Context) (int64, error)
+	SetAccountStatus(ctx context.Context, id uuid.UUID, status string) error
+	VerifyAccount(ctx context.Context, id uuid.UUID) (*models.Account, error)
+	CountAccountsByStatus(ctx context.Context) (map[string]int64, error)
 }
 
 type accountService struct {
-	repo repositories.AccountRepository
+	baseURL    string
+	httpClient *http.Client
+	repo       repositories.AccountRepository
+	retention  time.Duration
 }
 
-func NewAccountService(repo *repositories.AccountRepository) AccountService {
-	return &accountService{repo: *repo}
+func NewAccountService(cfg *config.Config, repo *repositories.AccountRepository, httpClient *http.Client) AccountService {
+	return &accountService{
+		baseURL:    cfg.MeroShareBaseURL,
+		httpClient: httpClient,
+		repo:       *repo,
+		retention:  cfg.AccountRetention,
+	}
 }
 
-func (s *accountService) LoginAccount(clientId uint16, username, password string) (string, error) {
+func (s *accountService) LoginAccount(ctx context.Context, clientId uint16, username, password string) (string, error) {
 
 	reqData := map[string]string{
 		"clientId": fmt.Sprintf("%d", clientId),
@@ -47,14 +66,13 @@ func (s *accountService) LoginAccount(clientId uint16, username, password string
 	if err != nil {
 		return "", err
 	}
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", "https://webbackend.cdsc.com.np/api/meroShare/auth/", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/auth/", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -88,15 +106,14 @@ func (s *accountService) LoginAccount(clientId uint16, username, password string
 	return authorization, nil
 }
 
-func (s *accountService) FetchUserDetails(authorization string) (responses.UserDetails, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", "https://webbackend.cdsc.com.np/api/meroShare/ownDetail/", nil)
+func (s *accountService) FetchUserDetails(ctx context.Context, authorization string) (responses.UserDetails, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/ownDetail/", nil)
 	if err != nil {
 		return responses.UserDetails{}, err
 	}
 	req.Header.Set("Authorization", authorization)
 
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return responses.UserDetails{}, err
 	}
@@ -114,15 +131,14 @@ func (s *accountService) FetchUserDetails(authorization string) (responses.UserD
 	return userDetails, nil
 }
 
-func (s *accountService) FetchBankDetails(authorization string, bankId string) ([]responses.BankDetails, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://webbackend.cdsc.com.np/api/meroShare/bank/%s", bankId), nil)
+func (s *accountService) FetchBankDetails(ctx context.Context, authorization string, bankId string) ([]responses.BankDetails, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/bank/%s", s.baseURL, bankId), nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Authorization", authorization)
 
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -140,30 +156,149 @@ func (s *accountService) FetchBankDetails(authorization string, bankId string) (
 	return bankDetails, nil
 }
 
-func (s *accountService) CreateAccount(account *models.Account) (uuid.UUID, error) {
-	return s.repo.CreateAccount(account)
+// FetchBankBalance returns the linked bank account's available balance. When MeroShare does not
+// report it, because the bank does not expose it or the call failed, it falls back to the balance
+// the user entered, and reports false when neither is known. A non-nil error says why MeroShare's
+// balance could not be read even when the fallback was used.
+func (s *accountService) FetchBankBalance(ctx context.Context, authorization string, account models.Account) (float64, bool, error) {
+	balance, offered, err := s.fetchBankBalance(ctx, authorization, account)
+	if err == nil && offered {
+		return balance, true, nil
+	}
+	if account.AvailableBalance != nil {
+		return *account.AvailableBalance, true, err
+	}
+	return 0, false, err
+}
+
+// fetchBankBalance asks MeroShare for the balance, reporting false without an error when the bank
+// does not offer it.
+func (s *accountService) fetchBankBalance(ctx context.Context, authorization string, account models.Account) (float64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/bank/balance/%d", s.baseURL, account.CustomerId), nil)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var balance responses.BankBalance
+		if err := json.NewDecoder(resp.Body).Decode(&balance); err != nil {
+			return 0, false, err
+		}
+		return balance.AvailableBalance, true, nil
+	case http.StatusNotFound, http.StatusNotImplemented, http.StatusForbidden:
+		return 0, false, nil
+	}
+	return 0, false, fmt.Errorf("failed to fetch bank balance: %d", resp.StatusCode)
+}
+
+func (s *accountService) CreateAccount(ctx context.Context, account *models.Account) (uuid.UUID, error) {
+	return s.repo.CreateAccount(ctx, account)
+}
+
+func (s *accountService) GetAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	return s.repo.GetAccountByID(ctx, id)
 }
 
-func (s *accountService) GetAccountByID(id uuid.UUID) (*models.Account, error) {
-	return s.repo.GetAccountByID(id)
+func (s *accountService) GetAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Account, error) {
+	return s.repo.GetAccountsByUserID(ctx, userID)
 }
 
-func (s *accountService) GetAccountsByUserID(userID uuid.UUID) ([]models.Account, error) {
-	return s.repo.GetAccountsByUserID(userID)
+func (s *accountService) GetAllAccounts(ctx context.Context) ([]models.Account, error) {
+	return s.repo.GetAllAccounts(ctx)
 }
 
-func (s *accountService) GetAllAccounts() ([]models.Account, error) {
-	return s.repo.GetAllAccounts()
+func (s *accountService) UpdateAccount(ctx context.Context, account *models.Account) error {
+	return s.repo.UpdateAccount(ctx, account)
 }
 
-func (s *accountService) UpdateAccount(account *models.Account) error {
-	return s.repo.UpdateAccount(account)
+func (s *accountService) DeleteAccount(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteAccount(ctx, id)
 }
 
-func (s *accountService) DeleteAccount(id uuid.UUID) error {
-	return s.repo.DeleteAccount(id)
+func (s *accountService) GetDeletedAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	account, err := s.repo.GetDeletedAccountByID(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Deleted account not found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return account, nil
 }
 
-func (s *accountService) SetAccountStatus(id uuid.UUID, status string) error {
-	return s.repo.SetAccountStatus(id, status)
+// RestoreAccount undeletes an account, unless its username has been linked again since it was deleted.
+func (s *accountService) RestoreAccount(ctx context.Context, account *models.Account) error {
+	inUse, err := s.repo.UsernameInUse(ctx, account.Username)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if inUse {
+		return errors.NewConflictError("Another account with this username has been added since it was deleted")
+	}
+	if err := s.repo.RestoreAccount(ctx, account.ID); err != nil {
+		return errors.NewInternalError(err)
+	}
+	account.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+// PurgeDeletedAccounts removes accounts that were deleted longer ago than the retention window, so
+// their credentials are not kept around once they can no longer be restored.
+func (s *accountService) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	return s.repo.PurgeAccountsDeletedBefore(ctx, time.Now().Add(-s.retention))
+}
+
+func (s *accountService) SetAccountStatus(ctx context.Context, id uuid.UUID, status string) error {
+	return s.repo.SetAccountStatus(ctx, id, status)
+}
+
+func (s *accountService) CountAccountsByStatus(ctx context.Context) (map[string]int64, error) {
+	return s.repo.CountAccountsByStatus(ctx)
+}
+
+// VerifyAccount logs in to MeroShare with the stored credentials, refreshes the account details and
+// expiry dates, and moves the account back to active when nothing is wrong with it any more. A wrong
+// transaction PIN can only be detected by applying, so an invalid_pin status is left alone.
+func (s *accountService) VerifyAccount(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	account, err := s.repo.GetAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, err := s.LoginAccount(ctx, account.ClientID, account.Username, account.Password)
+	if err != nil {
+		if err.Error() != "invalid credentials" {
+			return nil, err
+		}
+		account.Status = "invalid_credentials"
+		return account, s.repo.SetAccountStatus(ctx, account.ID, account.Status)
+	}
+
+	userDetails, err := s.FetchUserDetails(ctx, authorization)
+	if err != nil {
+		return nil, err
+	}
+	account.Name = userDetails.Name
+	account.Email = userDetails.Email
+	account.Contact = userDetails.Contact
+	account.Demat = userDetails.Demat
+	account.BOID = userDetails.BOID
+	account.DMATExpiryDate = userDetails.DematExpiryDate
+	account.PasswordExpiryDate = userDetails.PasswordExpiryDate
+	account.ExpiredDate = userDetails.ExpiredDate
+
+	if status, _ := checkAccountExpiry(*account); status != "" {
+		account.Status = status
+	} else if account.Status != "invalid_pin" {
+		account.Status = "active"
+	}
+	return account, s.repo.UpdateAccount(ctx, account)
 }
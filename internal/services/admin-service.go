@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/metrics"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// AdminService backs the operator endpoints. Callers are expected to have checked IsAdmin.
+type AdminService interface {
+	IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error)
+	ListUsers(ctx context.Context) ([]responses.AdminUser, error)
+	SetUserDisabled(ctx context.Context, adminID, userID uuid.UUID, disabled bool) (*responses.AdminUser, error)
+	CountAccountsByStatus(ctx context.Context) (map[string]int64, error)
+	RecentCDSCCalls(ctx context.Context, window time.Duration) ([]metrics.CDSCCallStats, error)
+}
+
+type adminService struct {
+	userRepo    repositories.UserRepository
+	accountRepo repositories.AccountRepository
+	recentCalls metrics.RecentCDSCCalls
+	redisClient *redis.Client
+	tokenExpiry time.Duration
+}
+
+func NewAdminService(cfg *config.Config, userRepo *repositories.UserRepository, accountRepo *repositories.AccountRepository, recentCalls metrics.RecentCDSCCalls, redisClient *redis.Client) AdminService {
+	return &adminService{
+		userRepo:    *userRepo,
+		accountRepo: *accountRepo,
+		recentCalls: recentCalls,
+		redisClient: redisClient,
+		tokenExpiry: cfg.TokenExpiry,
+	}
+}
+
+// IsAdmin reads the role from the database rather than the token, so demoting an admin takes effect
+// straight away.
+func (s *adminService) IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, errors.NewInternalError(err)
+	}
+	return user.Role == models.UserRoleAdmin && user.DisabledAt == nil, nil
+}
+
+func (s *adminService) ListUsers(ctx context.Context) ([]responses.AdminUser, error) {
+	users, err := s.userRepo.GetAllUsers(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	result := make([]responses.AdminUser, 0, len(users))
+	for _, user := range users {
+		result = append(result, adminUser(user))
+	}
+	return result, nil
+}
+
+// SetUserDisabled disables or re-enables a user. Access tokens issued before the user was disabled
+// are rejected through a Redis marker that lives as long as such a token can.
+func (s *adminService) SetUserDisabled(ctx context.Context, adminID, userID uuid.UUID, disabled bool) (*responses.AdminUser, error) {
+	if disabled && adminID == userID {
+		return nil, errors.NewBadRequestError("You cannot disable yourself")
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("User not found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+
+	var disabledAt *time.Time
+	if disabled {
+		if user.DisabledAt != nil {
+			disabledAt = user.DisabledAt
+		} else {
+			now := time.Now()
+			disabledAt = &now
+		}
+	}
+	if err := s.userRepo.SetUserDisabledAt(ctx, userID, disabledAt); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	user.DisabledAt = disabledAt
+
+	if disabled {
+		err = s.redisClient.Set(ctx, disabledUserKey(userID), "1", s.tokenExpiry).Err()
+	} else {
+		err = s.redisClient.Del(ctx, disabledUserKey(userID)).Err()
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	result := adminUser(*user)
+	return &result, nil
+}
+
+func (s *adminService) CountAccountsByStatus(ctx context.Context) (map[string]int64, error) {
+	counts, err := s.accountRepo.CountAccountsByStatus(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return counts, nil
+}
+
+func (s *adminService) RecentCDSCCalls(ctx context.Context, window time.Duration) ([]metrics.CDSCCallStats, error) {
+	stats, err := s.recentCalls.Since(ctx, time.Now().Add(-window))
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return stats, nil
+}
+
+func adminUser(user models.User) responses.AdminUser {
+	return responses.AdminUser{
+		ID:         user.ID,
+		Username:   user.Username,
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Role:       user.Role,
+		DisabledAt: user.DisabledAt,
+		CreatedAt:  user.CreatedAt,
+	}
+}
@@ -0,0 +1,554 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/policies"
+	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/metrics"
+	pkgredis "github.com/asrma7/meroshare-bot/pkg/redis"
+	"github.com/asrma7/meroshare-bot/pkg/tracing"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ApplyService runs the apply pipeline. Cancelling the context stops a run before its next account
+// or issue, but an application already submitted to MeroShare is always recorded first.
+type ApplyService interface {
+	Run(ctx context.Context) responses.ApplyPlan
+	RunForUser(ctx context.Context, userID uuid.UUID, dryRun bool) (responses.ApplyPlan, error)
+	SyncIssueCalendar(ctx context.Context) (int, error)
+	RecoverInFlight(ctx context.Context) (int, error)
+}
+
+type applyService struct {
+	dryRun         bool
+	lockTTL        time.Duration
+	redisClient    *redis.Client
+	accountService AccountService
+	shareService   ShareService
+	issueService   IssueService
+	accountPolicy  policies.AccountPolicy
+	metrics        metrics.Metrics
+}
+
+// applyCandidate is an issue an account is eligible for, with the kitta it would apply for.
+type applyCandidate struct {
+	share    responses.ApplicableShare
+	kitta    KittaDecision
+	closesAt time.Time
+	existing *models.AppliedShare
+}
+
+func NewApplyService(cfg *config.Config, accountService AccountService, shareService ShareService, issueService IssueService, accountPolicy policies.AccountPolicy, redisClient *redis.Client, m metrics.Metrics) ApplyService {
+	return &applyService{
+		dryRun:         cfg.DryRun,
+		lockTTL:        cfg.ApplyLockTTL,
+		redisClient:    redisClient,
+		accountService: accountService,
+		shareService:   shareService,
+		issueService:   issueService,
+		accountPolicy:  accountPolicy,
+		metrics:        m,
+	}
+}
+
+// Run applies for every active account. With DRY_RUN set it only plans.
+func (s *applyService) Run(ctx context.Context) responses.ApplyPlan {
+	allAccounts, err := s.accountService.GetAllAccounts(ctx)
+	if err != nil {
+		logs.ErrorContext(ctx, "Failed to get all accounts", map[string]any{"error": err})
+		return responses.ApplyPlan{DryRun: s.dryRun, StartedAt: time.Now()}
+	}
+
+	plan := s.run(ctx, allAccounts, s.dryRun)
+
+	if err := s.issueService.RefreshIssueStatuses(ctx); err != nil {
+		logs.ErrorContext(ctx, "Failed to refresh issue statuses", map[string]any{"error": err})
+	}
+	return plan
+}
+
+// RunForUser runs the pipeline for the accounts a user may apply for: their own and those they
+// manage for others. A dry run stops before ApplyForShare and writes nothing, returning what each
+// account would have done.
+func (s *applyService) RunForUser(ctx context.Context, userID uuid.UUID, dryRun bool) (responses.ApplyPlan, error) {
+	accounts, _, err := s.accountPolicy.Accounts(ctx, userID, policies.ActionApply)
+	if err != nil {
+		return responses.ApplyPlan{}, err
+	}
+	return s.run(ctx, accounts, dryRun || s.dryRun), nil
+}
+
+// SyncIssueCalendar refreshes the issue calendar without applying, using the first active account
+// that can log in, and returns how many issues MeroShare listed.
+func (s *applyService) SyncIssueCalendar(ctx context.Context) (int, error) {
+	accounts, err := s.accountService.GetAllAccounts(ctx)
+	if err != nil {
+		return 0, errors.NewInternalError(err)
+	}
+
+	for _, account := range accounts {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if account.Status != "active" {
+			continue
+		}
+		authorization, err := s.accountService.LoginAccount(ctx, account.ClientID, account.Username, account.Password)
+		if err != nil {
+			logs.WarnContext(ctx, "Failed to log in for issue sync", map[string]any{"error": err, "account_id": account.ID})
+			continue
+		}
+		applicableShares, err := s.shareService.FetchApplicableShares(ctx, authorization)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.issueService.SyncIssues(ctx, applicableShares.Shares); err != nil {
+			return 0, err
+		}
+		return len(applicableShares.Shares), s.issueService.RefreshIssueStatuses(ctx)
+	}
+	return 0, errors.NewBadRequestError("no active account could log in to MeroShare")
+}
+
+// RecoverInFlight settles applications left in flight by a process that stopped between reserving
+// and recording them. Rows whose account is locked belong to a live run and are left alone.
+func (s *applyService) RecoverInFlight(ctx context.Context) (int, error) {
+	shares, err := s.shareService.GetInFlightApplications(ctx)
+	if err != nil {
+		return 0, errors.NewInternalError(err)
+	}
+
+	recovered := 0
+	for i := range shares {
+		share := &shares[i]
+		lock, err := pkgredis.AcquireLock(ctx, s.redisClient, accountLockKey(share.AccountID), s.lockTTL)
+		if errors.Is(err, pkgredis.ErrLockHeld) {
+			continue
+		}
+		if err != nil {
+			return recovered, err
+		}
+
+		marked, err := s.shareService.MarkApplicationInterrupted(ctx, share)
+		if err == nil && marked {
+			recovered++
+			s.metrics.RecordApplyOutcome("interrupted")
+			logs.WarnContext(ctx, "Recovered interrupted application", map[string]any{"account_id": share.AccountID, "share_id": share.CompanyShareID})
+			err = s.shareService.AddApplyShareError(ctx, &models.AppliedShareError{
+				UserID:         share.UserID,
+				AccountID:      share.AccountID,
+				AppliedShareID: share.ID,
+				Message:        InterruptedRemark,
+				Attempt:        share.Attempts,
+			})
+		}
+		if er := lock.Release(context.Background()); er != nil {
+			logs.WarnContext(ctx, "Failed to release account lock", map[string]any{"error": er, "account_id": share.AccountID})
+		}
+		if err != nil {
+			return recovered, err
+		}
+	}
+	return recovered, nil
+}
+
+func accountLockKey(accountID uuid.UUID) string {
+	return "meroshare:apply:account:" + accountID.String()
+}
+
+func (s *applyService) run(ctx context.Context, accounts []models.Account, dryRun bool) responses.ApplyPlan {
+	ctx, span := tracing.Tracer().Start(ctx, "apply.run", trace.WithAttributes(
+		attribute.Bool("apply.dry_run", dryRun),
+		attribute.Int("apply.accounts", len(accounts)),
+	))
+	defer span.End()
+
+	plan := responses.ApplyPlan{DryRun: dryRun, StartedAt: time.Now()}
+	for _, account := range accounts {
+		accountPlan := responses.AccountPlan{
+			AccountID:   account.ID.String(),
+			AccountName: account.Name,
+			Status:      "ready",
+		}
+		if ctx.Err() != nil {
+			accountPlan.Status = "skipped"
+			accountPlan.Reason = "run was cancelled"
+		} else if account.Status != "active" {
+			accountPlan.Status = "skipped"
+			accountPlan.Reason = fmt.Sprintf("account status is %s", account.Status)
+		} else {
+			s.runForAccount(ctx, account, dryRun, &accountPlan)
+		}
+		plan.Accounts = append(plan.Accounts, accountPlan)
+	}
+	if !dryRun {
+		s.metrics.ObserveApplyRun(time.Since(plan.StartedAt))
+	}
+	return plan
+}
+
+func (s *applyService) runForAccount(ctx context.Context, account models.Account, dryRun bool, plan *responses.AccountPlan) {
+	ctx = tracing.WithAccountID(ctx, account.ID.String())
+	ctx = logs.WithFields(ctx, map[string]any{"account_id": account.ID})
+	ctx, span := tracing.Tracer().Start(ctx, "apply.account", trace.WithAttributes(attribute.String("account.id", account.ID.String())))
+	defer func() {
+		span.SetAttributes(attribute.String("apply.status", plan.Status), attribute.Int("apply.issues", len(plan.Issues)))
+		span.End()
+	}()
+
+	// Only one run at a time may apply for an account, whichever process or replica started it.
+	if !dryRun {
+		lock, err := pkgredis.AcquireLock(ctx, s.redisClient, accountLockKey(account.ID), s.lockTTL)
+		if err != nil {
+			plan.Status = "skipped"
+			plan.Reason = "another run is already processing this account"
+			if !errors.Is(err, pkgredis.ErrLockHeld) {
+				plan.Status = "error"
+				plan.Reason = err.Error()
+				logs.ErrorContext(ctx, "Failed to lock account", map[string]any{"error": err, "account_id": account.ID})
+			}
+			return
+		}
+		// A slow MeroShare can keep the run going past the lock's TTL, so the lock is renewed while
+		// it runs; if it is lost anyway, the run stops rather than race whoever took it over.
+		var stop context.CancelFunc
+		ctx, stop = lock.Hold(ctx, s.lockTTL)
+		defer func() {
+			stop()
+			if err := lock.Release(context.Background()); err != nil {
+				logs.WarnContext(ctx, "Failed to release account lock", map[string]any{"error": err, "account_id": account.ID})
+			}
+		}()
+	}
+
+	if status, reason := checkAccountExpiry(account); status != "" {
+		plan.Status = "skipped"
+		plan.Reason = reason
+		if !dryRun {
+			s.accountService.SetAccountStatus(ctx, account.ID, status)
+		}
+		return
+	}
+	authorization, err := s.accountService.LoginAccount(ctx, account.ClientID, account.Username, account.Password)
+	if err != nil {
+		plan.Status = "error"
+		plan.Reason = err.Error()
+		if err.Error() == "invalid credentials" {
+			if !dryRun {
+				s.accountService.SetAccountStatus(ctx, account.ID, "invalid_credentials")
+			}
+			return
+		}
+		logs.ErrorContext(ctx, "Failed to get authorization header", map[string]any{"error": err})
+		return
+	}
+	applicableShares, err := s.shareService.FetchApplicableShares(ctx, authorization)
+	if err != nil {
+		plan.Status = "error"
+		plan.Reason = err.Error()
+		logs.ErrorContext(ctx, "Failed to fetch applicable shares", map[string]any{"error": err})
+		return
+	}
+	if err := s.issueService.SyncIssues(ctx, applicableShares.Shares); err != nil {
+		logs.ErrorContext(ctx, "Failed to sync issue calendar", map[string]any{"error": err})
+	}
+
+	candidates := s.selectIssues(ctx, account, authorization, applicableShares.Shares, dryRun, plan)
+	candidates = s.fitToBalance(ctx, account, authorization, candidates, dryRun, plan)
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			plan.Issues = append(plan.Issues, issuePlan(candidate.share, candidate.kitta, "skip", "run was cancelled"))
+			continue
+		}
+		if dryRun {
+			plan.Issues = append(plan.Issues, issuePlan(candidate.share, candidate.kitta, "apply", candidateReason(candidate)))
+			continue
+		}
+		s.apply(ctx, account, authorization, candidate, plan)
+	}
+}
+
+// checkAccountExpiry returns the status an account should move to when its MeroShare subscription,
+// password or DMAT account has expired.
+func checkAccountExpiry(account models.Account) (string, string) {
+	if account.ExpiredDate.Before(time.Now()) {
+		return "meroshare_expired", "MeroShare subscription has expired"
+	}
+	if account.PasswordExpiryDate.Before(time.Now()) {
+		return "password_expired", "MeroShare password has expired"
+	}
+	bsDate := strings.Split(account.DMATExpiryDate, "-")
+	if len(bsDate) != 3 {
+		return "", ""
+	}
+	dmatExpiryDate, err := utils.ConvertBSToAD(utils.StringToInt(bsDate[0]), utils.StringToInt(bsDate[1]), utils.StringToInt(bsDate[2]))
+	if err != nil {
+		logs.Error("Failed to convert DMAT expiry date", map[string]any{"error": err})
+		return "", ""
+	}
+	if dmatExpiryDate.Before(time.Now()) {
+		return "dmat_expired", "DMAT account has expired"
+	}
+	return "", ""
+}
+
+// selectIssues filters the open issues down to the ones the account should apply for, recording
+// issues it is not eligible for so they are not re-evaluated on every run.
+func (s *applyService) selectIssues(ctx context.Context, account models.Account, authorization string, shares []responses.ApplicableShare, dryRun bool, plan *responses.AccountPlan) []applyCandidate {
+	var candidates []applyCandidate
+	for _, share := range shares {
+		if !s.shareService.IsApplicableShare(account, share) {
+			plan.Issues = append(plan.Issues, issuePlan(share, KittaDecision{}, "skip", "share type is not enabled for this account"))
+			continue
+		}
+		alreadyApplied, existing, err := s.shareService.CheckIfShareAlreadyApplied(ctx, account.ID.String(), fmt.Sprintf("%d", share.CompanyShareID))
+		if err != nil {
+			logs.ErrorContext(ctx, "Failed to check if share already applied", map[string]any{"error": err})
+			plan.Issues = append(plan.Issues, issuePlan(share, KittaDecision{}, "skip", err.Error()))
+			continue
+		}
+		if alreadyApplied {
+			plan.Issues = append(plan.Issues, issuePlan(share, KittaDecision{}, "skip", fmt.Sprintf("already recorded as %s", existing.Status)))
+			continue
+		}
+		closesAt, err := utils.ParseIssueDate(share.IssueCloseDate)
+		if err != nil {
+			logs.WarnContext(ctx, "Failed to parse issue close date", map[string]any{"error": err, "share_id": share.CompanyShareID})
+		} else if existing != nil && closesAt.Before(time.Now()) {
+			plan.Issues = append(plan.Issues, issuePlan(share, KittaDecision{}, "skip", "issue has closed"))
+			continue
+		}
+		candidate := applyCandidate{share: share, closesAt: closesAt, existing: existing}
+		if eligible, reason := s.shareService.CheckEligibility(account, share); !eligible {
+			s.skip(ctx, account, candidate, "skipped_ineligible", reason, dryRun, plan)
+			continue
+		}
+		candidate.kitta, err = s.shareService.ResolveKitta(ctx, authorization, account, share)
+		if err != nil {
+			// Reported as an error rather than a skip, so a MeroShare endpoint that keeps failing, such as
+			// right share eligibility, is not mistaken for an issue the account need not apply for.
+			logs.ErrorContext(ctx, "Failed to resolve kitta", map[string]any{"error": err, "account_id": account.ID, "share_id": share.CompanyShareID})
+			s.metrics.RecordApplyOutcome("error")
+			plan.Issues = append(plan.Issues, issuePlan(share, KittaDecision{}, "error", err.Error()))
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// fitToBalance drops the lowest-priority issues when the bank balance cannot cover every application,
+// and every issue when no balance is known.
+// Ordinary shares come first, then issues closing soonest, then cheaper applications.
+func (s *applyService) fitToBalance(ctx context.Context, account models.Account, authorization string, candidates []applyCandidate, dryRun bool, plan *responses.AccountPlan) []applyCandidate {
+	if len(candidates) == 0 {
+		return candidates
+	}
+	balance, known, err := s.accountService.FetchBankBalance(ctx, authorization, account)
+	if err != nil {
+		logs.WarnContext(ctx, "Failed to fetch bank balance", map[string]any{"error": err, "account_id": account.ID, "fallback": known})
+	}
+	if !known {
+		// Without any balance nothing is known to be funded, so nothing is applied for.
+		for _, candidate := range candidates {
+			s.skip(ctx, account, candidate, "skipped_insufficient_funds", "bank balance is unknown; set the account's available balance", dryRun, plan)
+		}
+		return nil
+	}
+	plan.Balance = &balance
+
+	var required float64
+	for _, candidate := range candidates {
+		required += candidate.kitta.Amount
+	}
+	if required <= balance {
+		return candidates
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if rankA, rankB := assetClassRank(a.share), assetClassRank(b.share); rankA != rankB {
+			return rankA < rankB
+		}
+		if !a.closesAt.Equal(b.closesAt) {
+			if a.closesAt.IsZero() || b.closesAt.IsZero() {
+				return !a.closesAt.IsZero()
+			}
+			return a.closesAt.Before(b.closesAt)
+		}
+		return a.kitta.Amount < b.kitta.Amount
+	})
+
+	remaining := balance
+	var funded []applyCandidate
+	for _, candidate := range candidates {
+		if candidate.kitta.Amount <= remaining {
+			remaining -= candidate.kitta.Amount
+			funded = append(funded, candidate)
+			continue
+		}
+		reason := fmt.Sprintf("requires NPR %.2f but only NPR %.2f is available", candidate.kitta.Amount, remaining)
+		s.skip(ctx, account, candidate, "skipped_insufficient_funds", reason, dryRun, plan)
+	}
+	return funded
+}
+
+func (s *applyService) apply(ctx context.Context, account models.Account, authorization string, candidate applyCandidate, plan *responses.AccountPlan) {
+	share, kitta := candidate.share, candidate.kitta
+
+	// The in-flight row is written before submitting so a concurrent run, or a retry after a crash,
+	// sees the application and cannot submit it a second time.
+	appliedShare := newAppliedShare(account, share, kitta, "in_flight")
+	reserved, err := s.shareService.ReserveApplication(ctx, appliedShare, candidate.existing)
+	if err != nil {
+		plan.Issues = append(plan.Issues, issuePlan(share, kitta, "skip", err.Error()))
+		logs.ErrorContext(ctx, "Failed to reserve application", map[string]any{"error": err, "account_id": account.ID, "share_id": share.CompanyShareID})
+		return
+	}
+	if !reserved {
+		plan.Issues = append(plan.Issues, issuePlan(share, kitta, "skip", "another run is already applying for this issue"))
+		return
+	}
+
+	// From here on the outcome must be recorded even if the run is being cancelled.
+	ctx = context.WithoutCancel(ctx)
+	ctx, span := tracing.Tracer().Start(ctx, "apply.submit", trace.WithAttributes(
+		attribute.String("account.id", account.ID.String()),
+		attribute.Int("share.id", int(share.CompanyShareID)),
+		attribute.String("share.scrip", share.Scrip),
+		attribute.String("share.kitta", kitta.Kitta),
+	))
+	defer span.End()
+	result, err := s.shareService.ApplyForShare(ctx, account, share, kitta.Kitta, authorization)
+	if err != nil && candidate.existing != nil && candidate.existing.Remark == InterruptedRemark && strings.Contains(err.Error(), "already applied") {
+		// The interrupted attempt reached MeroShare after all.
+		result, err = nil, nil
+	}
+	tracing.RecordError(span, err)
+	if err != nil {
+		plan.Issues = append(plan.Issues, issuePlan(share, kitta, "failed", err.Error()))
+		if err.Error() == "invalid transaction PIN" {
+			s.accountService.SetAccountStatus(ctx, account.ID, "invalid_pin")
+		}
+		logs.ErrorContext(ctx, "Failed to apply for share", map[string]any{"error": err, "share_id": share.CompanyShareID})
+		s.metrics.RecordApplyOutcome("failed")
+		appliedShare.Status = "failed"
+		appliedShare.Permanent = IsPermanentApplyError(err)
+		if er := s.shareService.UpdateAppliedShare(ctx, appliedShare); er != nil {
+			logs.ErrorContext(ctx, "Failed to update applied share", map[string]any{"error": er})
+			return
+		}
+		s.shareService.AddApplyShareError(ctx, &models.AppliedShareError{
+			UserID:         account.UserID,
+			AccountID:      account.ID,
+			AppliedShareID: appliedShare.ID,
+			Message:        err.Error(),
+			Attempt:        appliedShare.Attempts,
+			Permanent:      appliedShare.Permanent,
+		})
+		return
+	}
+	plan.Issues = append(plan.Issues, issuePlan(share, kitta, "applied", candidateReason(candidate)))
+	logs.InfoContext(ctx, "Successfully applied for share", map[string]any{"share_id": share.CompanyShareID, "message": result["message"]})
+	s.metrics.RecordApplyOutcome("applied")
+	appliedShare.Status = "applied"
+	if err := s.shareService.UpdateAppliedShare(ctx, appliedShare); err != nil {
+		logs.ErrorContext(ctx, "Failed to update applied share", map[string]any{"error": err})
+	}
+}
+
+func (s *applyService) skip(ctx context.Context, account models.Account, candidate applyCandidate, status, reason string, dryRun bool, plan *responses.AccountPlan) {
+	plan.Issues = append(plan.Issues, issuePlan(candidate.share, candidate.kitta, "skip", reason))
+	if dryRun {
+		return
+	}
+	s.metrics.RecordApplyOutcome(status)
+	if _, err := s.record(ctx, account, candidate, status, reason, status == "skipped_ineligible"); err != nil {
+		logs.ErrorContext(ctx, "Failed to add applied share", map[string]any{"error": err})
+	}
+}
+
+// record stores a skipped issue, updating the row left by an earlier attempt when there is one.
+func (s *applyService) record(ctx context.Context, account models.Account, candidate applyCandidate, status, remark string, permanent bool) (*models.AppliedShare, error) {
+	appliedShare := newAppliedShare(account, candidate.share, candidate.kitta, status)
+	appliedShare.Remark = remark
+	appliedShare.Permanent = permanent
+	if candidate.existing == nil {
+		id, err := s.shareService.AddAppliedShare(ctx, appliedShare)
+		appliedShare.ID = id
+		return appliedShare, err
+	}
+
+	appliedShare.ID = candidate.existing.ID
+	appliedShare.CreatedAt = candidate.existing.CreatedAt
+	appliedShare.Attempts = candidate.existing.Attempts
+	return appliedShare, s.shareService.UpdateAppliedShare(ctx, appliedShare)
+}
+
+func candidateReason(candidate applyCandidate) string {
+	if candidate.existing != nil {
+		return fmt.Sprintf("retry after %d previous attempt(s)", candidate.existing.Attempts)
+	}
+	return ""
+}
+
+func issuePlan(share responses.ApplicableShare, kitta KittaDecision, action, reason string) responses.IssuePlan {
+	return responses.IssuePlan{
+		CompanyShareID: share.CompanyShareID,
+		Scrip:          share.Scrip,
+		CompanyName:    share.CompanyName,
+		ShareTypeName:  share.ShareTypeName,
+		ShareGroupName: share.ShareGroupName,
+		SubGroup:       share.SubGroup,
+		Kitta:          kitta.Kitta,
+		KittaSource:    kitta.Source,
+		Amount:         kitta.Amount,
+		Action:         action,
+		Reason:         reason,
+	}
+}
+
+func assetClassRank(share responses.ApplicableShare) int {
+	switch assetClass(share) {
+	case AssetClassEquity:
+		return 0
+	case AssetClassMutualFund:
+		return 1
+	case AssetClassDebenture:
+		return 2
+	}
+	return 3
+}
+
+func newAppliedShare(account models.Account, share responses.ApplicableShare, kitta KittaDecision, status string) *models.AppliedShare {
+	return &models.AppliedShare{
+		UserID:         account.UserID,
+		AccountID:      account.ID,
+		CompanyName:    share.CompanyName,
+		CompanyShareID: share.CompanyShareID,
+		Scrip:          share.Scrip,
+		AppliedKitta:   kitta.Kitta,
+		KittaSource:    kitta.Source,
+		EligibleKitta:  kitta.EligibleKitta,
+		PricePerUnit:   kitta.PricePerUnit,
+		Amount:         kitta.Amount,
+		ShareGroupName: share.ShareGroupName,
+		ShareTypeName:  share.ShareTypeName,
+		SubGroup:       share.SubGroup,
+		Status:         status,
+		Attempts:       1,
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionRegister       = "auth.register"
+	AuditActionLogin          = "auth.login"
+	AuditActionRefreshToken   = "auth.refresh_token"
+	AuditActionAccountCreate  = "account.create"
+	AuditActionAccountUpdate  = "account.update"
+	AuditActionAccountDelete  = "account.delete"
+	AuditActionAccountVerify  = "account.verify"
+	AuditActionAccountRestore = "account.restore"
+	AuditActionGrantInvite    = "grant.invite"
+	AuditActionGrantAccept    = "grant.accept"
+	AuditActionGrantRemove    = "grant.remove"
+	AuditActionApplyRun       = "share.apply_run"
+	AuditActionErrorsMarkSeen = "share.errors_mark_seen"
+	AuditActionResetLogs      = "user.reset_logs"
+	AuditActionAdminAccess    = "admin.access"
+	AuditActionAdminUsers     = "admin.list_users"
+	AuditActionAdminStats     = "admin.account_stats"
+	AuditActionAdminRun       = "admin.trigger_run"
+	AuditActionAdminJob       = "admin.get_job"
+	AuditActionAdminDisable   = "admin.disable_user"
+	AuditActionAdminEnable    = "admin.enable_user"
+	AuditActionAdminCDSC      = "admin.cdsc_errors"
+	AuditActionAdminRole      = "admin.change_role"
+)
+
+const defaultAuditLimit = 50
+
+type AuditService interface {
+	Record(ctx context.Context, event models.AuditEvent)
+	GetAuditEventsByUserID(ctx context.Context, userID uuid.UUID, filter requests.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type auditService struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo *repositories.AuditRepository) AuditService {
+	return &auditService{repo: *repo}
+}
+
+// Record writes an audit event. A failure to write it is logged rather than returned, so the
+// action being audited is not undone or reported as failed because of it.
+func (s *auditService) Record(ctx context.Context, event models.AuditEvent) {
+	event.CorrelationID = logs.CorrelationID(ctx)
+	// The event is written even when the request was cancelled after the action took effect.
+	if err := s.repo.CreateAuditEvent(context.WithoutCancel(ctx), &event); err != nil {
+		logs.ErrorContext(ctx, "Failed to record audit event", map[string]any{"error": err, "action": event.Action, "outcome": event.Outcome})
+	}
+}
+
+func (s *auditService) GetAuditEventsByUserID(ctx context.Context, userID uuid.UUID, filter requests.AuditFilter) ([]models.AuditEvent, error) {
+	var before *time.Time
+	if filter.Before != "" {
+		t, err := time.Parse(time.RFC3339, filter.Before)
+		if err != nil {
+			return nil, errors.NewValidationError("before", "must be an RFC 3339 timestamp")
+		}
+		before = &t
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultAuditLimit
+	}
+	events, err := s.repo.GetAuditEventsByUserID(ctx, userID, filter.Action, before, limit)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return events, nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -8,39 +9,48 @@ import (
 	"github.com/asrma7/meroshare-bot/internal/repositories"
 	"github.com/asrma7/meroshare-bot/pkg/config"
 	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
 	"github.com/asrma7/meroshare-bot/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
 type AuthService interface {
-	RegisterUser(username, password, email, firstname, lastname string) (uuid.UUID, error)
-	LoginUser(username, password string) (string, string, error)
-	RefreshToken(refreshToken string) (newAccessToken string, newRefreshToken string, err error)
-	GetProfile(userID uuid.UUID) (*models.User, error)
+	RegisterUser(ctx context.Context, username, password, email, firstname, lastname string) (uuid.UUID, error)
+	LoginUser(ctx context.Context, username, password string) (uuid.UUID, string, string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (userID uuid.UUID, newAccessToken string, newRefreshToken string, err error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	ValidateToken(token string) (*CustomClaims, error)
 }
 
 type authService struct {
-	cfg        *config.Config
-	userRepo   repositories.UserRepository
-	jwtService JWTService
+	cfg         *config.Config
+	userRepo    repositories.UserRepository
+	jwtService  JWTService
+	redisClient *redis.Client
 }
 
 func NewAuthService(cfg *config.Config, userRepo *repositories.UserRepository, redis *redis.Client) AuthService {
 	return &authService{
-		cfg:        cfg,
-		userRepo:   *userRepo,
-		jwtService: NewJWTService(cfg, redis),
+		cfg:         cfg,
+		userRepo:    *userRepo,
+		jwtService:  NewJWTService(cfg, redis),
+		redisClient: redis,
 	}
 }
 
-func (s *authService) RegisterUser(username, password, email, firstname, lastname string) (uuid.UUID, error) {
-	_, err := s.userRepo.GetUserByUsername(username)
+// disabledUserKey marks a disabled user in Redis for as long as an access token issued before they
+// were disabled can still be valid. Logging in and refreshing tokens check the database instead.
+func disabledUserKey(userID uuid.UUID) string {
+	return "disabled:" + userID.String()
+}
+
+func (s *authService) RegisterUser(ctx context.Context, username, password, email, firstname, lastname string) (uuid.UUID, error) {
+	_, err := s.userRepo.GetUserByUsername(ctx, username)
 	if err == nil {
 		return uuid.Nil, errors.NewConflictError("username already exists")
 	}
-	_, err = s.userRepo.GetUserByEmail(email)
+	_, err = s.userRepo.GetUserByEmail(ctx, email)
 	if err == nil {
 		return uuid.Nil, errors.NewConflictError("email already exists")
 	}
@@ -59,7 +69,7 @@ func (s *authService) RegisterUser(username, password, email, firstname, lastnam
 		LastName:  lastname,
 	}
 
-	userID, err := s.userRepo.CreateUser(user)
+	userID, err := s.userRepo.CreateUser(ctx, user)
 	if err != nil {
 		return uuid.Nil, errors.NewInternalError(err)
 	}
@@ -67,55 +77,56 @@ func (s *authService) RegisterUser(username, password, email, firstname, lastnam
 	return userID, nil
 }
 
-func (s *authService) LoginUser(identifier, password string) (string, string, error) {
-	user, err := s.userRepo.GetUserByUsername(identifier)
+// LoginUser returns the user's ID along with the tokens. The ID is also returned when only the
+// password was wrong, so the failed attempt can be audited against the user.
+func (s *authService) LoginUser(ctx context.Context, identifier, password string) (uuid.UUID, string, string, error) {
+	user, err := s.userRepo.GetUserByUsername(ctx, identifier)
 	if err != nil {
-		user, err = s.userRepo.GetUserByEmail(identifier)
+		user, err = s.userRepo.GetUserByEmail(ctx, identifier)
 		if err != nil {
-			return "", "", errors.NewUnauthorizedError("invalid username/email or password")
+			return uuid.Nil, "", "", errors.NewUnauthorizedError("invalid username/email or password")
 		}
 	}
 	if err := utils.CheckPasswordHash(password, user.Password); err != nil {
-		return "", "", errors.NewUnauthorizedError("invalid username/email or password")
+		return user.ID, "", "", errors.NewUnauthorizedError("invalid username/email or password")
+	}
+	if user.DisabledAt != nil {
+		return user.ID, "", "", errors.NewForbiddenError("user is disabled")
 	}
 	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID)
 	if err != nil {
-		return "", "", errors.NewInternalError(err)
+		return user.ID, "", "", errors.NewInternalError(err)
 	}
-	return accessToken, refreshToken, nil
+	return user.ID, accessToken, refreshToken, nil
 }
 
-func (s *authService) RefreshToken(refreshToken string) (string, string, error) {
-	claims, err := s.jwtService.ExtractClaims(refreshToken)
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (uuid.UUID, string, string, error) {
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return "", "", errors.NewUnauthorizedError("invalid refresh token")
-	}
-
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		return "", "", errors.NewUnauthorizedError("invalid refresh token claims")
-	}
-
-	uid, err := uuid.Parse(userID)
-	if err != nil {
-		return "", "", errors.NewUnauthorizedError("invalid user ID in token")
+		if _, ok := errors.IsAppError(err); ok {
+			return uuid.Nil, "", "", err
+		}
+		return uuid.Nil, "", "", errors.NewUnauthorizedError("invalid refresh token")
 	}
 
-	user, err := s.userRepo.GetUserByID(uid)
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
 	if err != nil || user == nil {
-		return "", "", errors.NewUnauthorizedError("user not found")
+		return uuid.Nil, "", "", errors.NewUnauthorizedError("user not found")
+	}
+	// Checked before rotating, so a disabled user's refresh token is not used up.
+	if user.DisabledAt != nil {
+		return user.ID, "", "", errors.NewForbiddenError("user is disabled")
 	}
 
 	accessToken, newRefreshToken, err := s.jwtService.RefreshToken(refreshToken)
 	if err != nil {
-		return "", "", err
+		return user.ID, "", "", err
 	}
-
-	return accessToken, newRefreshToken, nil
+	return user.ID, accessToken, newRefreshToken, nil
 }
 
-func (s *authService) GetProfile(userID uuid.UUID) (*models.User, error) {
-	user, err := s.userRepo.GetUserByID(userID)
+func (s *authService) GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, errors.NewNotFoundError(fmt.Sprintf("user with ID %s not found", userID))
@@ -130,5 +141,12 @@ func (s *authService) ValidateToken(token string) (*CustomClaims, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Failing open keeps the API up while Redis is down; the token expires soon either way.
+	disabled, err := s.redisClient.Exists(context.Background(), disabledUserKey(claims.UserID)).Result()
+	if err != nil {
+		logs.Warn("Failed to check whether user is disabled", map[string]any{"error": err, "user_id": claims.UserID})
+	} else if disabled > 0 {
+		return nil, errors.NewUnauthorizedError("user is disabled")
+	}
 	return claims, nil
 }
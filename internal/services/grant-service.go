@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GrantService interface {
+	InviteUser(ctx context.Context, account *models.Account, invitedBy uuid.UUID, identifier, role string) (*models.AccountGrant, error)
+	GetGrantByID(ctx context.Context, id uuid.UUID) (*models.AccountGrant, error)
+	GetGrantsByAccountID(ctx context.Context, accountID uuid.UUID) ([]models.AccountGrant, error)
+	GetGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]models.AccountGrant, error)
+	AcceptGrant(ctx context.Context, grant *models.AccountGrant) error
+	DeleteGrant(ctx context.Context, id uuid.UUID) error
+}
+
+type grantService struct {
+	repo     repositories.GrantRepository
+	userRepo repositories.UserRepository
+}
+
+func NewGrantService(repo *repositories.GrantRepository, userRepo *repositories.UserRepository) GrantService {
+	return &grantService{
+		repo:     *repo,
+		userRepo: *userRepo,
+	}
+}
+
+// InviteUser creates a pending grant on the account for the user with the given username or email
+// address. The grant gives no access until that user accepts it.
+func (s *grantService) InviteUser(ctx context.Context, account *models.Account, invitedBy uuid.UUID, identifier, role string) (*models.AccountGrant, error) {
+	var (
+		user *models.User
+		err  error
+	)
+	if strings.Contains(identifier, "@") {
+		user, err = s.userRepo.GetUserByEmail(ctx, identifier)
+	} else {
+		user, err = s.userRepo.GetUserByUsername(ctx, identifier)
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("User not found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	if user.ID == account.UserID {
+		return nil, errors.NewConflictError("The account already belongs to this user")
+	}
+
+	if _, err := s.repo.GetGrant(ctx, account.ID, user.ID); err == nil {
+		return nil, errors.NewConflictError("This user already has access to the account or has been invited")
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err)
+	}
+
+	grant := &models.AccountGrant{
+		AccountID: account.ID,
+		UserID:    user.ID,
+		Role:      role,
+		Status:    models.GrantStatusPending,
+		InvitedBy: invitedBy,
+	}
+	if err := s.repo.CreateGrant(ctx, grant); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return grant, nil
+}
+
+func (s *grantService) GetGrantByID(ctx context.Context, id uuid.UUID) (*models.AccountGrant, error) {
+	grant, err := s.repo.GetGrantByID(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Grant not found")
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return grant, nil
+}
+
+func (s *grantService) GetGrantsByAccountID(ctx context.Context, accountID uuid.UUID) ([]models.AccountGrant, error) {
+	return s.repo.GetGrantsByAccountID(ctx, accountID)
+}
+
+func (s *grantService) GetGrantsByUserID(ctx context.Context, userID uuid.UUID) ([]models.AccountGrant, error) {
+	return s.repo.GetGrantsByUserID(ctx, userID)
+}
+
+func (s *grantService) AcceptGrant(ctx context.Context, grant *models.AccountGrant) error {
+	if grant.Status == models.GrantStatusAccepted {
+		return errors.NewConflictError("The invitation has already been accepted")
+	}
+	if err := s.repo.AcceptGrant(ctx, grant.ID); err != nil {
+		return errors.NewInternalError(err)
+	}
+	grant.Status = models.GrantStatusAccepted
+	return nil
+}
+
+func (s *grantService) DeleteGrant(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteGrant(ctx, id)
+}
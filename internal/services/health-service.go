@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/database"
+	pkgredis "github.com/asrma7/meroshare-bot/pkg/redis"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	schedulerLastRunKey = "meroshare:scheduler:last_run"
+	// healthCheckTimeout bounds each readiness check, so one hung dependency cannot hang the probe.
+	healthCheckTimeout = 3 * time.Second
+	// schedulerGrace is how late a scheduled run may be before the scheduler is reported as stuck.
+	schedulerGrace = 15 * time.Minute
+)
+
+// HealthService reports whether the process can serve traffic. Database, Redis and the schema
+// version are critical; the scheduler and MeroShare reachability only degrade the report.
+type HealthService interface {
+	Ready(ctx context.Context) responses.ReadinessReport
+	RecordSchedulerRun(at time.Time) error
+}
+
+type healthCheck struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) (map[string]any, error)
+}
+
+type healthService struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	leader        pkgredis.LeaderElector
+	migrator      database.Migrator
+	migratorErr   error
+	applySchedule string
+	cdscURL       string
+	httpClient    *http.Client
+}
+
+func NewHealthService(cfg *config.Config, db *gorm.DB, redisClient *redis.Client, leader pkgredis.LeaderElector) HealthService {
+	migrator, err := database.NewMigrator(db, cfg.DBDriver)
+	s := &healthService{
+		db:            db,
+		redisClient:   redisClient,
+		leader:        leader,
+		migrator:      migrator,
+		migratorErr:   err,
+		applySchedule: cfg.ApplySchedule,
+		httpClient:    &http.Client{},
+	}
+	if cfg.ReadyCheckCDSC {
+		s.cdscURL = cfg.MeroShareBaseURL
+	}
+	return s
+}
+
+func (s *healthService) Ready(ctx context.Context) responses.ReadinessReport {
+	checks := []healthCheck{
+		{name: "database", critical: true, run: s.checkDatabase},
+		{name: "redis", critical: true, run: s.checkRedis},
+		{name: "migrations", critical: true, run: s.checkMigrations},
+		{name: "scheduler", run: s.checkScheduler},
+	}
+	if s.cdscURL != "" {
+		checks = append(checks, healthCheck{name: "cdsc", run: s.checkCDSC})
+	}
+
+	report := responses.ReadinessReport{Ready: true, CheckedAt: time.Now()}
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		start := time.Now()
+		details, err := check.run(checkCtx)
+		cancel()
+
+		result := responses.HealthCheck{
+			Name:       check.name,
+			Status:     "ok",
+			Critical:   check.critical,
+			DurationMs: time.Since(start).Milliseconds(),
+			Details:    details,
+		}
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			if check.critical {
+				report.Ready = false
+			} else {
+				report.Degraded = true
+			}
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// RecordSchedulerRun notes when the leader last ran the apply schedule, for the scheduler check.
+func (s *healthService) RecordSchedulerRun(at time.Time) error {
+	return s.redisClient.Set(context.Background(), schedulerLastRunKey, at, 0).Err()
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) (map[string]any, error) {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	stats := sqlDB.Stats()
+	return map[string]any{"open_connections": stats.OpenConnections, "in_use": stats.InUse}, nil
+}
+
+func (s *healthService) checkRedis(ctx context.Context) (map[string]any, error) {
+	return nil, s.redisClient.Ping(ctx).Err()
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) (map[string]any, error) {
+	if s.migratorErr != nil {
+		return nil, s.migratorErr
+	}
+	current, err := s.migrator.CurrentVersion()
+	if err != nil {
+		return nil, err
+	}
+	latest := s.migrator.LatestVersion()
+	details := map[string]any{"current": current, "latest": latest}
+	if current != latest {
+		return details, fmt.Errorf("schema is at version %d, this binary expects %d", current, latest)
+	}
+	return details, nil
+}
+
+// checkScheduler fails when no replica holds the leader lease, or when a scheduled run is overdue.
+func (s *healthService) checkScheduler(ctx context.Context) (map[string]any, error) {
+	leader := s.leader.Status().Leader
+	details := map[string]any{"leader": leader}
+	if leader == "" {
+		return details, fmt.Errorf("no scheduler holds the leader lease")
+	}
+
+	lastRun, err := s.redisClient.Get(ctx, schedulerLastRunKey).Time()
+	if err == redis.Nil {
+		return details, nil
+	}
+	if err != nil {
+		return details, err
+	}
+	schedule, err := cron.ParseStandard(s.applySchedule)
+	if err != nil {
+		return details, err
+	}
+	nextRun := schedule.Next(lastRun.In(utils.NepalLocation()))
+	details["last_run"] = lastRun
+	details["next_run"] = nextRun
+	if time.Now().After(nextRun.Add(schedulerGrace)) {
+		return details, fmt.Errorf("the run due at %s has not happened", nextRun.Format(time.RFC3339))
+	}
+	return details, nil
+}
+
+// checkCDSC reports whether MeroShare answers at all; any HTTP response counts as reachable.
+func (s *healthService) checkCDSC(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cdscURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return map[string]any{"status_code": resp.StatusCode}, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/internal/repositories"
+	"github.com/asrma7/meroshare-bot/internal/requests"
+	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
+)
+
+type IssueService interface {
+	SyncIssues(ctx context.Context, shares []responses.ApplicableShare) error
+	RefreshIssueStatuses(ctx context.Context) error
+	GetIssues(ctx context.Context, filter requests.IssueFilter) ([]models.Issue, error)
+}
+
+type issueService struct {
+	repo repositories.IssueRepository
+}
+
+func NewIssueService(repo *repositories.IssueRepository) IssueService {
+	return &issueService{repo: *repo}
+}
+
+// SyncIssues upserts every issue from an applicable-issue response into the calendar.
+func (s *issueService) SyncIssues(ctx context.Context, shares []responses.ApplicableShare) error {
+	now := time.Now()
+	for _, share := range shares {
+		issue := &models.Issue{
+			CompanyShareID: share.CompanyShareID,
+			CompanyName:    share.CompanyName,
+			Scrip:          share.Scrip,
+			ShareTypeName:  share.ShareTypeName,
+			ShareGroupName: share.ShareGroupName,
+			SubGroup:       share.SubGroup,
+			IssueOpenDate:  parseIssueDate(share.IssueOpenDate),
+			IssueCloseDate: parseIssueDate(share.IssueCloseDate),
+			FirstSeenAt:    now,
+			LastSeenAt:     now,
+		}
+		issue.Status = issueStatus(issue, now)
+		if err := s.repo.UpsertIssue(ctx, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *issueService) RefreshIssueStatuses(ctx context.Context) error {
+	return s.repo.UpdateIssueStatuses(ctx, time.Now())
+}
+
+func (s *issueService) GetIssues(ctx context.Context, filter requests.IssueFilter) ([]models.Issue, error) {
+	if err := s.repo.UpdateIssueStatuses(ctx, time.Now()); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	issues, err := s.repo.GetIssues(ctx, filter)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return issues, nil
+}
+
+func issueStatus(issue *models.Issue, now time.Time) string {
+	switch {
+	case issue.IssueCloseDate != nil && !issue.IssueCloseDate.After(now):
+		return "closed"
+	case issue.IssueOpenDate != nil && issue.IssueOpenDate.After(now):
+		return "upcoming"
+	}
+	return "open"
+}
+
+func parseIssueDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := utils.ParseIssueDate(value)
+	if err != nil {
+		logs.Warn("Failed to parse issue date", map[string]any{"error": err, "value": value})
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/queue"
+	"github.com/asrma7/meroshare-bot/pkg/tracing"
+)
+
+const (
+	JobTypeApply  = "apply"
+	JobTypeVerify = "verify"
+	JobTypeSync   = "sync"
+)
+
+// VerifyJobPayload names the account a verify job checks.
+type VerifyJobPayload struct {
+	AccountID string `json:"account_id"`
+}
+
+// JobService puts work on the queue for the worker process and reports on its progress. An apply
+// job without a user runs for every account.
+type JobService interface {
+	Enqueue(ctx context.Context, jobType string, userID string, payload any) (*queue.Job, error)
+	GetJob(ctx context.Context, id string, userID string) (*queue.Job, error)
+	GetAnyJob(ctx context.Context, id string) (*queue.Job, error)
+}
+
+type jobService struct {
+	queue queue.Queue
+}
+
+func NewJobService(jobQueue queue.Queue) JobService {
+	return &jobService{queue: jobQueue}
+}
+
+func (s *jobService) Enqueue(ctx context.Context, jobType string, userID string, payload any) (*queue.Job, error) {
+	job := &queue.Job{
+		Type:          jobType,
+		UserID:        userID,
+		CorrelationID: logs.CorrelationID(ctx),
+		Trace:         tracing.Inject(ctx),
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		job.Payload = data
+	}
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return job, nil
+}
+
+// GetJob returns a job the user enqueued; other users' jobs are reported as not found.
+func (s *jobService) GetJob(ctx context.Context, id string, userID string) (*queue.Job, error) {
+	job, err := s.GetAnyJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, errors.NewNotFoundError("Job not found")
+	}
+	return job, nil
+}
+
+// GetAnyJob returns a job whoever enqueued it, for admins following runs they or the scheduler
+// started.
+func (s *jobService) GetAnyJob(ctx context.Context, id string) (*queue.Job, error) {
+	job, err := s.queue.Get(ctx, id)
+	if errors.Is(err, queue.ErrJobNotFound) {
+		return nil, errors.NewNotFoundError("Job not found")
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	return job, nil
+}
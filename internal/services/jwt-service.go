@@ -21,6 +21,7 @@ type CustomClaims struct {
 type JWTService interface {
 	GenerateToken(userID uuid.UUID) (accessToken string, refreshToken string, err error)
 	ValidateToken(token string) (*CustomClaims, error)
+	ValidateRefreshToken(refreshToken string) (*CustomClaims, error)
 	RefreshToken(refreshToken string) (newAccessToken string, newRefreshToken string, err error)
 	ExtractClaims(token string) (jwt.MapClaims, error)
 	ParseToken(token string) (*jwt.Token, error)
@@ -111,7 +112,8 @@ func (s *jwtService) ValidateToken(tokenString string) (*CustomClaims, error) {
 	return claims, nil
 }
 
-func (s *jwtService) RefreshToken(refreshToken string) (string, string, error) {
+// ValidateRefreshToken checks the refresh token's signature and expiry without using it up.
+func (s *jwtService) ValidateRefreshToken(refreshToken string) (*CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(refreshToken, &CustomClaims{}, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -121,14 +123,22 @@ func (s *jwtService) RefreshToken(refreshToken string) (string, string, error) {
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			return "", "", errors.NewUnauthorizedError("token expired")
+			return nil, errors.NewUnauthorizedError("token expired")
 		}
-		return "", "", fmt.Errorf("invalid token: %w", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
 	claims, ok := token.Claims.(*CustomClaims)
 	if !ok || !token.Valid {
-		return "", "", errors.NewUnauthorizedError("invalid token claims")
+		return nil, errors.NewUnauthorizedError("invalid token claims")
+	}
+	return claims, nil
+}
+
+func (s *jwtService) RefreshToken(refreshToken string) (string, string, error) {
+	claims, err := s.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", err
 	}
 
 	jti := claims.ID
@@ -2,10 +2,11 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
 	"strings"
 
@@ -13,85 +14,205 @@ import (
 	"github.com/asrma7/meroshare-bot/internal/repositories"
 	"github.com/asrma7/meroshare-bot/internal/requests"
 	"github.com/asrma7/meroshare-bot/internal/responses"
+	"github.com/asrma7/meroshare-bot/pkg/config"
 	"github.com/asrma7/meroshare-bot/pkg/errors"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/utils"
 	"github.com/google/uuid"
 )
 
 type ShareService interface {
-	AddAppliedShare(share *models.AppliedShare) (uuid.UUID, error)
-	AddApplyShareError(error *models.AppliedShareError) error
-	GetAppliedSharesByUserID(userID string) ([]models.AppliedShare, error)
-	CheckIfShareAlreadyApplied(accountID string, companyShareID string) (bool, error)
-	GetAppliedShareByID(id string) (*models.AppliedShare, *models.AppliedShareError, error)
-	GetAppliedShareErrorsByUserID(userID string) ([]models.AppliedShareError, error)
-	MarkShareErrorsAsSeenByUserID(userID string) error
-	FetchApplicableShares(authorization string) (responses.ApplicableSharesResponse, error)
-	ApplyForShare(account models.Account, share responses.ApplicableShare, authorization string) (map[string]any, error)
-	DeleteAllAppliedSharesByUserID(userID uuid.UUID) error
-	DeleteAllAppliedShareErrorsByUserID(userID uuid.UUID) error
+	AddAppliedShare(ctx context.Context, share *models.AppliedShare) (uuid.UUID, error)
+	AddApplyShareError(ctx context.Context, error *models.AppliedShareError) error
+	GetAppliedSharesByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShare, error)
+	CheckIfShareAlreadyApplied(ctx context.Context, accountID string, companyShareID string) (bool, *models.AppliedShare, error)
+	UpdateAppliedShare(ctx context.Context, share *models.AppliedShare) error
+	ReserveApplication(ctx context.Context, share *models.AppliedShare, existing *models.AppliedShare) (bool, error)
+	GetInFlightApplications(ctx context.Context) ([]models.AppliedShare, error)
+	MarkApplicationInterrupted(ctx context.Context, share *models.AppliedShare) (bool, error)
+	GetAppliedShareByID(ctx context.Context, id string) (*models.AppliedShare, []models.AppliedShareError, error)
+	GetAppliedShareErrorsByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShareError, error)
+	MarkShareErrorsAsSeenByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) error
+	FetchApplicableShares(ctx context.Context, authorization string) (responses.ApplicableSharesResponse, error)
+	FetchRightShareEligibility(ctx context.Context, authorization string, account models.Account, share responses.ApplicableShare) (int, error)
+	FetchIssueDetails(ctx context.Context, authorization string, companyShareID uint16) (responses.IssueDetails, error)
+	IsApplicableShare(account models.Account, share responses.ApplicableShare) bool
+	CheckEligibility(account models.Account, share responses.ApplicableShare) (bool, string)
+	ResolveKitta(ctx context.Context, authorization string, account models.Account, share responses.ApplicableShare) (KittaDecision, error)
+	ApplyForShare(ctx context.Context, account models.Account, share responses.ApplicableShare, kitta string, authorization string) (map[string]any, error)
+	DeleteAllAppliedSharesByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteAllAppliedShareErrorsByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+const (
+	ShareTypeIPO   = "IPO"
+	ShareTypeFPO   = "FPO"
+	ShareTypeRight = "RIGHT"
+
+	AssetClassEquity     = "equity"
+	AssetClassMutualFund = "mutual_fund"
+	AssetClassDebenture  = "debenture"
+
+	QuotaGeneralPublic     = "general_public"
+	QuotaForeignEmployment = "foreign_employment"
+	QuotaProjectAffected   = "project_affected"
+	QuotaMutualFunds       = "mutual_funds"
+	QuotaEmployees         = "employees"
+
+	KittaSourcePreferred        = "preferred"
+	KittaSourceRightEligibility = "right_eligibility"
+	KittaSourceConfiguredUnits  = "configured_units"
+	KittaSourceMinimumUnit      = "minimum_unit"
+	KittaSourceAmountLimit      = "amount_limit"
+)
+
+// parValue is the face value of an ordinary share, used to price an IPO or FPO application when the
+// issue details cannot be fetched.
+const parValue = 100
+
+// KittaDecision records how many kitta an account applies for on an issue and where the number came from.
+type KittaDecision struct {
+	Kitta         string
+	Source        string
+	EligibleKitta int
+	PricePerUnit  float64
+	Amount        float64
 }
 
 type shareService struct {
-	repo repositories.ShareRepository
+	baseURL     string
+	httpClient  *http.Client
+	repo        repositories.ShareRepository
+	maxAttempts int
+}
+
+func NewShareService(cfg *config.Config, repo *repositories.ShareRepository, httpClient *http.Client) ShareService {
+	return &shareService{
+		baseURL:     cfg.MeroShareBaseURL,
+		httpClient:  httpClient,
+		repo:        *repo,
+		maxAttempts: cfg.ApplyMaxAttempts,
+	}
+}
+
+func (s *shareService) AddAppliedShare(ctx context.Context, share *models.AppliedShare) (uuid.UUID, error) {
+	return s.repo.AddAppliedShare(ctx, share)
 }
 
-func NewShareService(repo *repositories.ShareRepository) ShareService {
-	return &shareService{repo: *repo}
+func (s *shareService) AddApplyShareError(ctx context.Context, error *models.AppliedShareError) error {
+	return s.repo.AddApplyShareError(ctx, error)
 }
 
-func (s *shareService) AddAppliedShare(share *models.AppliedShare) (uuid.UUID, error) {
-	return s.repo.AddAppliedShare(share)
+func (s *shareService) GetAppliedSharesByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShare, error) {
+	return s.repo.GetAppliedSharesByUserID(ctx, userID, accountIDs)
 }
 
-func (s *shareService) AddApplyShareError(error *models.AppliedShareError) error {
-	return s.repo.AddApplyShareError(error)
+func (s *shareService) UpdateAppliedShare(ctx context.Context, share *models.AppliedShare) error {
+	return s.repo.UpdateAppliedShare(ctx, share)
 }
 
-func (s *shareService) GetAppliedSharesByUserID(userID string) ([]models.AppliedShare, error) {
-	return s.repo.GetAppliedSharesByUserID(userID)
+// ReserveApplication records the application as in flight before it is submitted, either as a new
+// row or by taking over the row of an earlier attempt. It returns false when another run got there
+// first, in which case the caller must not submit.
+func (s *shareService) ReserveApplication(ctx context.Context, share *models.AppliedShare, existing *models.AppliedShare) (bool, error) {
+	share.Status = "in_flight"
+	if existing == nil {
+		return s.repo.InsertAppliedShareIfAbsent(ctx, share)
+	}
+	share.ID = existing.ID
+	share.CreatedAt = existing.CreatedAt
+	share.Attempts = existing.Attempts + 1
+	return s.repo.ClaimAppliedShare(ctx, share, existing.Status, existing.Attempts)
+}
+
+func (s *shareService) GetInFlightApplications(ctx context.Context) ([]models.AppliedShare, error) {
+	return s.repo.GetAppliedSharesByStatus(ctx, "in_flight")
 }
 
-func (s *shareService) GetAppliedShareByID(id string) (*models.AppliedShare, *models.AppliedShareError, error) {
-	share, err := s.repo.GetAppliedShareByID(id)
+// InterruptedRemark marks an application whose run stopped after reserving it but before recording
+// the result, so it is unknown whether MeroShare received it.
+const InterruptedRemark = "interrupted before the result was recorded"
+
+// MarkApplicationInterrupted turns an in-flight row left by a run that died into a failed attempt
+// the next run retries. It returns false when the row is no longer in flight.
+func (s *shareService) MarkApplicationInterrupted(ctx context.Context, share *models.AppliedShare) (bool, error) {
+	attempts := share.Attempts
+	share.Status = "failed"
+	share.Remark = InterruptedRemark
+	share.Permanent = false
+	return s.repo.ClaimAppliedShare(ctx, share, "in_flight", attempts)
+}
+
+func (s *shareService) GetAppliedShareByID(ctx context.Context, id string) (*models.AppliedShare, []models.AppliedShareError, error) {
+	share, err := s.repo.GetAppliedShareByID(ctx, id)
 	if err != nil {
 		return nil, nil, errors.NewNotFoundError("Applied share not found")
 	}
-	errors, err := s.repo.GetAppliedShareErrorsByAppliedShareID(id)
-	if err != nil && err.Error() != "record not found" {
+	errors, err := s.repo.GetAppliedShareErrorsByAppliedShareID(ctx, id)
+	if err != nil {
 		return nil, nil, err
 	}
 	return share, errors, nil
 }
 
-func (s *shareService) CheckIfShareAlreadyApplied(accountID string, companyShareID string) (bool, error) {
-	share, err := s.repo.GetAppliedShareByAccountIDAndCompanyShareID(accountID, companyShareID)
+// CheckIfShareAlreadyApplied reports whether an account is done with an issue. A transient failure
+// that has not used up its attempts, or a skip for insufficient funds, is not done: it is returned so
+// the next run can retry it in place.
+func (s *shareService) CheckIfShareAlreadyApplied(ctx context.Context, accountID string, companyShareID string) (bool, *models.AppliedShare, error) {
+	share, err := s.repo.GetAppliedShareByAccountIDAndCompanyShareID(ctx, accountID, companyShareID)
 	if err != nil {
 		if strings.Contains(err.Error(), "record not found") {
-			return false, nil
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	switch share.Status {
+	case "failed":
+		if !share.Permanent && share.Attempts < s.maxAttempts {
+			return false, share, nil
+		}
+	case "skipped_insufficient_funds":
+		return false, share, nil
+	}
+	return true, share, nil
+}
+
+// IsPermanentApplyError reports whether retrying an application can never succeed, e.g. a wrong
+// transaction PIN or an issue the account is not eligible for.
+func IsPermanentApplyError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, permanent := range []string{
+		"transaction pin",
+		"invalid credentials",
+		"not eligible",
+		"reserved for",
+		"below the issue minimum",
+		"already applied",
+	} {
+		if strings.Contains(message, permanent) {
+			return true
 		}
-		return false, err
 	}
-	return share != nil, nil
+	return false
 }
 
-func (s *shareService) GetAppliedShareErrorsByUserID(userID string) ([]models.AppliedShareError, error) {
-	return s.repo.GetAppliedShareErrorsByUserID(userID)
+func (s *shareService) GetAppliedShareErrorsByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) ([]models.AppliedShareError, error) {
+	return s.repo.GetAppliedShareErrorsByUserID(ctx, userID, accountIDs)
 }
 
-func (s *shareService) MarkShareErrorsAsSeenByUserID(userID string) error {
-	return s.repo.MarkShareErrorsAsSeenByUserID(userID)
+func (s *shareService) MarkShareErrorsAsSeenByUserID(ctx context.Context, userID string, accountIDs []uuid.UUID) error {
+	return s.repo.MarkShareErrorsAsSeenByUserID(ctx, userID, accountIDs)
 }
 
-func (s *shareService) DeleteAllAppliedSharesByUserID(userID uuid.UUID) error {
-	return s.repo.DeleteAllAppliedSharesByUserID(userID)
+func (s *shareService) DeleteAllAppliedSharesByUserID(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.DeleteAllAppliedSharesByUserID(ctx, userID)
 }
 
-func (s *shareService) DeleteAllAppliedShareErrorsByUserID(userID uuid.UUID) error {
-	return s.repo.DeleteAllAppliedShareErrorsByUserID(userID)
+func (s *shareService) DeleteAllAppliedShareErrorsByUserID(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.DeleteAllAppliedShareErrorsByUserID(ctx, userID)
 }
 
-func (s *shareService) FetchApplicableShares(authorization string) (responses.ApplicableSharesResponse, error) {
-	client := http.Client{}
+func (s *shareService) FetchApplicableShares(ctx context.Context, authorization string) (responses.ApplicableSharesResponse, error) {
 	payload := strings.NewReader(`{
     "filterFieldParams": [
         {
@@ -126,14 +247,14 @@ func (s *shareService) FetchApplicableShares(authorization string) (responses.Ap
         }
     ]
 }`)
-	req, err := http.NewRequest("POST", "https://webbackend.cdsc.com.np/api/meroShare/companyShare/applicableIssue/", payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/companyShare/applicableIssue/", payload)
 	if err != nil {
 		return responses.ApplicableSharesResponse{}, err
 	}
 	req.Header.Set("Authorization", authorization)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return responses.ApplicableSharesResponse{}, err
 	}
@@ -147,7 +268,238 @@ func (s *shareService) FetchApplicableShares(authorization string) (responses.Ap
 	return applicableShares, nil
 }
 
-func (s *shareService) ApplyForShare(account models.Account, share responses.ApplicableShare, authorization string) (map[string]any, error) {
+// FetchRightShareEligibility asks MeroShare how many right share units the account's demat is
+// entitled to. CDSC does not publish its API and this path has not been confirmed against the live
+// service; the mock server only mirrors it. A failure is reported as an error for the issue rather
+// than applying with a guessed kitta.
+func (s *shareService) FetchRightShareEligibility(ctx context.Context, authorization string, account models.Account, share responses.ApplicableShare) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/applicantForm/rightShare/eligibility/%d/%s", s.baseURL, share.CompanyShareID, account.Demat), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch right share eligibility: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to fetch right share eligibility: %d", resp.StatusCode)
+	}
+
+	var eligibility responses.RightShareEligibility
+	if err := json.NewDecoder(resp.Body).Decode(&eligibility); err != nil {
+		return 0, fmt.Errorf("failed to decode right share eligibility: %w", err)
+	}
+
+	return eligibility.EligibleKitta, nil
+}
+
+// FetchIssueDetails reads an open issue's unit limits and price from GET /meroShare/active/{companyShareId},
+// the endpoint MeroShare's own apply form loads them from.
+func (s *shareService) FetchIssueDetails(ctx context.Context, authorization string, companyShareID uint16) (responses.IssueDetails, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/active/%d", s.baseURL, companyShareID), nil)
+	if err != nil {
+		return responses.IssueDetails{}, err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return responses.IssueDetails{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responses.IssueDetails{}, fmt.Errorf("failed to fetch issue details: %d", resp.StatusCode)
+	}
+
+	var details responses.IssueDetails
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return responses.IssueDetails{}, err
+	}
+
+	return details, nil
+}
+
+func (s *shareService) IsApplicableShare(account models.Account, share responses.ApplicableShare) bool {
+	if share.Action != "" {
+		return false
+	}
+	switch assetClass(share) {
+	case AssetClassMutualFund:
+		return account.ApplyMutualFunds
+	case AssetClassDebenture:
+		return account.ApplyDebentures
+	case AssetClassEquity:
+		switch shareType(share) {
+		case ShareTypeIPO, ShareTypeFPO, ShareTypeRight:
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveKitta decides how many units to apply for and what the application costs. Ordinary IPO and
+// FPO applications use the preferred kitta; if their issue details cannot be fetched they are priced
+// at par so the balance check still counts them.
+func (s *shareService) ResolveKitta(ctx context.Context, authorization string, account models.Account, share responses.ApplicableShare) (KittaDecision, error) {
+	ordinary := assetClass(share) == AssetClassEquity && shareType(share) != ShareTypeRight
+	details, err := s.FetchIssueDetails(ctx, authorization, share.CompanyShareID)
+	if err != nil {
+		if !ordinary {
+			return KittaDecision{}, err
+		}
+		logs.WarnContext(ctx, "Failed to fetch issue details, pricing at par", map[string]any{"error": err, "share_id": share.CompanyShareID})
+		details.SharePerUnit = parValue
+	}
+
+	var decision KittaDecision
+	switch assetClass(share) {
+	case AssetClassMutualFund:
+		decision, err = unitsForAssetClass(account.MutualFundUnits, account.MutualFundMaxAmount, details)
+	case AssetClassDebenture:
+		decision, err = unitsForAssetClass(account.DebentureUnits, account.DebentureMaxAmount, details)
+	default:
+		if ordinary {
+			decision = KittaDecision{Kitta: account.PreferredKitta, Source: KittaSourcePreferred}
+		} else {
+			decision, err = s.rightShareKitta(ctx, authorization, account, share)
+		}
+	}
+	if err != nil {
+		return KittaDecision{}, err
+	}
+
+	decision.PricePerUnit = details.SharePerUnit
+	decision.Amount = float64(utils.StringToInt(decision.Kitta)) * details.SharePerUnit
+	return decision, nil
+}
+
+func (s *shareService) rightShareKitta(ctx context.Context, authorization string, account models.Account, share responses.ApplicableShare) (KittaDecision, error) {
+	eligible, err := s.FetchRightShareEligibility(ctx, authorization, account, share)
+	if err != nil {
+		return KittaDecision{}, err
+	}
+	if eligible <= 0 {
+		return KittaDecision{}, fmt.Errorf("not eligible for right share")
+	}
+
+	fraction := account.RightShareFraction
+	if fraction <= 0 || fraction > 1 {
+		fraction = 1
+	}
+	kitta := max(int(math.Floor(float64(eligible)*fraction)), 1)
+
+	return KittaDecision{
+		Kitta:         fmt.Sprintf("%d", kitta),
+		Source:        KittaSourceRightEligibility,
+		EligibleKitta: eligible,
+	}, nil
+}
+
+// unitsForAssetClass turns an account's per-asset-class setting into a kitta that respects the issue's
+// minimum unit, maximum unit and lot size. Zero units with an amount limit applies for as many units as
+// the amount buys; zero units without one applies for the minimum unit.
+func unitsForAssetClass(units uint32, maxAmount float64, details responses.IssueDetails) (KittaDecision, error) {
+	kitta := int(units)
+	source := KittaSourceConfiguredUnits
+	if kitta == 0 {
+		kitta = details.MinUnit
+		source = KittaSourceMinimumUnit
+	}
+
+	if maxAmount > 0 && details.SharePerUnit > 0 {
+		affordable := int(math.Floor(maxAmount / details.SharePerUnit))
+		if units == 0 || affordable < kitta {
+			kitta = affordable
+			source = KittaSourceAmountLimit
+		}
+	}
+
+	if details.MultipleOf > 1 {
+		kitta -= kitta % details.MultipleOf
+	}
+	if details.MaxUnit > 0 && kitta > details.MaxUnit {
+		kitta = details.MaxUnit - details.MaxUnit%max(details.MultipleOf, 1)
+	}
+	if kitta <= 0 || kitta < details.MinUnit {
+		return KittaDecision{}, fmt.Errorf("configured units or amount is below the issue minimum of %d units", details.MinUnit)
+	}
+
+	return KittaDecision{Kitta: fmt.Sprintf("%d", kitta), Source: source}, nil
+}
+
+// assetClass groups an issue by ShareGroupName, e.g. "Ordinary Shares", "Mutual Fund" or "Debentures".
+func assetClass(share responses.ApplicableShare) string {
+	group := strings.ToUpper(share.ShareGroupName)
+	switch {
+	case strings.Contains(group, "MUTUAL"):
+		return AssetClassMutualFund
+	case strings.Contains(group, "DEBENTURE"):
+		return AssetClassDebenture
+	case strings.Contains(group, "ORDINARY"):
+		return AssetClassEquity
+	}
+	return group
+}
+
+func (s *shareService) CheckEligibility(account models.Account, share responses.ApplicableShare) (bool, string) {
+	switch reservedQuota(share) {
+	case QuotaForeignEmployment:
+		if !account.ForeignEmployment {
+			return false, "issue is reserved for Nepalese citizens in foreign employment"
+		}
+	case QuotaProjectAffected:
+		district := strings.TrimSpace(account.District)
+		if district == "" {
+			return false, "issue is reserved for locals of project-affected areas and the account has no district"
+		}
+		if !strings.Contains(strings.ToLower(share.SubGroup), strings.ToLower(district)) {
+			return false, fmt.Sprintf("issue is reserved for locals of project-affected areas, which do not include %s", district)
+		}
+	case QuotaMutualFunds:
+		return false, "issue is reserved for mutual funds"
+	case QuotaEmployees:
+		return false, "issue is reserved for employees of the issuer"
+	}
+	return true, ""
+}
+
+// reservedQuota classifies SubGroup, e.g. "For General Public", "For Nepalese citizens working abroad"
+// or "For Local People of Dolakha district".
+func reservedQuota(share responses.ApplicableShare) string {
+	subGroup := strings.ToLower(share.SubGroup)
+	switch {
+	case strings.Contains(subGroup, "abroad"), strings.Contains(subGroup, "foreign employment"), strings.Contains(subGroup, "migrant"):
+		return QuotaForeignEmployment
+	case strings.Contains(subGroup, "local"), strings.Contains(subGroup, "project affected"):
+		return QuotaProjectAffected
+	case strings.Contains(subGroup, "mutual fund"):
+		return QuotaMutualFunds
+	case strings.Contains(subGroup, "employee"):
+		return QuotaEmployees
+	}
+	return QuotaGeneralPublic
+}
+
+// shareType normalises ShareTypeName, which MeroShare reports as e.g. "IPO", "FPO" or "RIGHT SHARE".
+func shareType(share responses.ApplicableShare) string {
+	name := strings.ToUpper(strings.TrimSpace(share.ShareTypeName))
+	switch {
+	case strings.Contains(name, "RIGHT"):
+		return ShareTypeRight
+	case strings.Contains(name, "FPO"):
+		return ShareTypeFPO
+	case strings.Contains(name, "IPO"):
+		return ShareTypeIPO
+	}
+	return name
+}
+
+func (s *shareService) ApplyForShare(ctx context.Context, account models.Account, share responses.ApplicableShare, kitta string, authorization string) (map[string]any, error) {
 	req := requests.ApplyShareRequest{
 		Demat:           account.Demat,
 		BOID:            account.BOID,
@@ -155,7 +507,7 @@ func (s *shareService) ApplyForShare(account models.Account, share responses.App
 		CustomerID:      account.CustomerId,
 		AccountBranchID: account.AccountBranchId,
 		AccountTypeID:   account.AccountTypeId,
-		AppliedKitta:    account.PreferredKitta,
+		AppliedKitta:    kitta,
 		CRNNumber:       account.CRNNumber,
 		TransactionPIN:  account.TransactionPIN,
 		CompanyShareID:  fmt.Sprintf("%d", share.CompanyShareID),
@@ -164,18 +516,17 @@ func (s *shareService) ApplyForShare(account models.Account, share responses.App
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		log.Fatalf("Error marshaling request: %v", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	client := http.Client{}
-	httpReq, err := http.NewRequest("POST", "https://webbackend.cdsc.com.np/api/meroShare/applicantForm/share/apply/", bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/applicantForm/share/apply/", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	httpReq.Header.Set("Authorization", authorization)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(httpReq)
+	resp, err := s.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +554,7 @@ func (s *shareService) ApplyForShare(account models.Account, share responses.App
 					return nil, fmt.Errorf("failed to apply for share: %d", resp.StatusCode)
 				}
 				if jsonErr.Message == "Application in process. Please try again later." {
-					fmt.Println("Application in process. Skipping duplicate application.")
+					logs.InfoContext(ctx, "Application in process, skipping duplicate application", map[string]any{"share_id": share.CompanyShareID})
 					return nil, nil
 				}
 				return nil, fmt.Errorf("conflict: %s", jsonErr.Message)
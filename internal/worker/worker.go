@@ -0,0 +1,222 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/services"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/asrma7/meroshare-bot/pkg/queue"
+	"github.com/asrma7/meroshare-bot/pkg/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pollTimeout bounds how long a worker blocks waiting for a job, and so how long Stop waits.
+const pollTimeout = 5 * time.Second
+
+// settleTimeout is how long a job that hit the job timeout gets to record its outcome before
+// RequeueStale considers its worker dead.
+const settleTimeout = time.Minute
+
+type Worker interface {
+	Start()
+	Stop(ctx context.Context) error
+	ProcessNext(ctx context.Context, timeout time.Duration) (bool, error)
+}
+
+type worker struct {
+	identity       string
+	concurrency    int
+	jobTimeout     time.Duration
+	maxAttempts    int
+	queue          queue.Queue
+	applyService   services.ApplyService
+	accountService services.AccountService
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorker(cfg *config.Config, jobQueue queue.Queue, applyService services.ApplyService, accountService services.AccountService) Worker {
+	concurrency := cfg.WorkerConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	maxAttempts := cfg.JobMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &worker{
+		identity:       cfg.InstanceID,
+		concurrency:    concurrency,
+		jobTimeout:     cfg.JobTimeout,
+		maxAttempts:    maxAttempts,
+		queue:          jobQueue,
+		applyService:   applyService,
+		accountService: accountService,
+	}
+}
+
+func (w *worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	for i := 0; i < w.concurrency; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for ctx.Err() == nil {
+				if _, err := w.ProcessNext(ctx, pollTimeout); err != nil && ctx.Err() == nil {
+					logs.Error("Failed to process job", map[string]any{"error": err})
+					time.Sleep(time.Second)
+				}
+			}
+		}()
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			w.requeueStale(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	logs.Info("Worker started", map[string]any{"identity": w.identity, "concurrency": w.concurrency})
+}
+
+// Stop stops taking new jobs and cancels the running ones, which record the application they are
+// submitting and requeue the rest. It waits for them to return, or for ctx to be done.
+func (w *worker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		logs.Info("Worker stopped", map[string]any{"identity": w.identity})
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ProcessNext runs the next job on the queue, waiting up to timeout for one. It reports whether a
+// job was run; a job that fails is recorded on the job, not returned.
+func (w *worker) ProcessNext(ctx context.Context, timeout time.Duration) (bool, error) {
+	job, err := w.queue.Dequeue(ctx, w.identity, timeout)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, job.Trace), "job "+job.Type,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("job.id", job.ID),
+			attribute.String("job.type", job.Type),
+			attribute.Int("job.attempt", job.Attempts),
+		),
+	)
+	defer span.End()
+
+	correlationID := job.CorrelationID
+	if correlationID == "" {
+		correlationID = job.ID
+	}
+	ctx = logs.WithCorrelationID(ctx, correlationID)
+	ctx = logs.WithFields(ctx, map[string]any{"job_id": job.ID, "type": job.Type})
+	if job.UserID != "" {
+		ctx = logs.WithUserID(ctx, job.UserID)
+	}
+
+	logs.InfoContext(ctx, "Running job", map[string]any{"attempt": job.Attempts})
+	// Cutting jobs off at the timeout means one RequeueStale hands out again has really stopped.
+	runCtx, cancel := context.WithTimeout(ctx, w.jobTimeout)
+	result, jobErr := w.handle(runCtx, job)
+	cancel()
+	tracing.RecordError(span, jobErr)
+	// The job is settled even when the worker is being stopped.
+	ctx = context.WithoutCancel(ctx)
+	if errors.Is(jobErr, context.Canceled) {
+		// The worker is shutting down; leave the rest of the job to the next worker.
+		logs.WarnContext(ctx, "Job interrupted, requeueing", nil)
+		return true, w.queue.Requeue(ctx, job)
+	}
+	if jobErr != nil {
+		logs.ErrorContext(ctx, "Job failed", map[string]any{"error": jobErr})
+	}
+	return true, w.queue.Complete(ctx, job, result, jobErr)
+}
+
+// handle runs the job. Apply and sync jobs stop early when ctx is cancelled and return its error.
+func (w *worker) handle(ctx context.Context, job *queue.Job) (any, error) {
+	switch job.Type {
+	case services.JobTypeApply:
+		if job.UserID == "" {
+			return w.applyService.Run(ctx), ctx.Err()
+		}
+		userID, err := uuid.Parse(job.UserID)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := w.applyService.RunForUser(ctx, userID, false)
+		if err == nil {
+			err = ctx.Err()
+		}
+		return plan, err
+	case services.JobTypeVerify:
+		var payload services.VerifyJobPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, err
+		}
+		accountID, err := uuid.Parse(payload.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		account, err := w.accountService.VerifyAccount(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"account_id": account.ID, "status": account.Status}, nil
+	case services.JobTypeSync:
+		issues, err := w.applyService.SyncIssueCalendar(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"issues": issues}, nil
+	}
+	return nil, fmt.Errorf("unknown job type %q", job.Type)
+}
+
+func (w *worker) requeueStale(ctx context.Context) {
+	requeued, failed, err := w.queue.RequeueStale(ctx, w.jobTimeout+settleTimeout, w.maxAttempts)
+	if requeued > 0 {
+		logs.Warn("Requeued stale jobs", map[string]any{"count": requeued})
+	}
+	if failed > 0 {
+		logs.Error("Failed stale jobs that ran out of attempts", map[string]any{"count": failed, "max_attempts": w.maxAttempts})
+	}
+	if err != nil && ctx.Err() == nil {
+		logs.Error("Failed to requeue stale jobs", map[string]any{"error": err})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/asrma7/meroshare-bot/pkg/logs"
@@ -11,16 +12,41 @@ import (
 )
 
 type Config struct {
-	Environment   string
-	Port          string
-	RedisAddr     string
-	RedisPassword string
-	RedisDB       int
-	DBConnString  string
-	AccessSecret  string
-	RefreshSecret string
-	TokenExpiry   time.Duration
-	RefreshExpiry time.Duration
+	Environment        string
+	InstanceID         string
+	Port               string
+	MetricsPort        string
+	RedisAddr          string
+	RedisPassword      string
+	RedisDB            int
+	DBDriver           string
+	DBConnString       string
+	SQLitePath         string
+	DBAutoMigrate      bool
+	AccessSecret       string
+	RefreshSecret      string
+	TokenExpiry        time.Duration
+	RefreshExpiry      time.Duration
+	ApplyMaxAttempts   int
+	ApplyLockTTL       time.Duration
+	LeaderLeaseTTL     time.Duration
+	WorkerConcurrency  int
+	JobTimeout         time.Duration
+	JobMaxAttempts     int
+	JobRetention       time.Duration
+	AccountRetention   time.Duration
+	ShutdownTimeout    time.Duration
+	ApplySchedule      string
+	CloseDayRunTime    string
+	PurgeSchedule      string
+	DryRun             bool
+	MeroShareBaseURL   string
+	ReadyCheckCDSC     bool
+	TracingExporter    string
+	TracingServiceName string
+	TracingSampleRatio float64
+	OTLPEndpoint       string
+	Logging            logs.Options
 }
 
 func LoadConfig() *Config {
@@ -29,17 +55,56 @@ func LoadConfig() *Config {
 		logs.Debug("Error loading env file", map[string]interface{}{"error": err})
 	}
 
+	environment := getEnv("ENVIRONMENT", "development")
+	logLevel, logFormat := "debug", "text"
+	if environment == "prod" {
+		logLevel, logFormat = "info", "json"
+	}
+
 	return &Config{
-		Environment:   getEnv("ENVIRONMENT", "development"),
-		Port:          getEnv("PORT", "8080"),
-		RedisAddr:     getRedisAddr(),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),
-		RedisDB:       getEnvInt("REDIS_DB", 0),
-		DBConnString:  getDBConnString(),
-		AccessSecret:  getEnv("ACCESS_SECRET", "your_access_secret"),
-		RefreshSecret: getEnv("REFRESH_SECRET", "your_refresh_secret"),
-		TokenExpiry:   time.Minute * 15,
-		RefreshExpiry: time.Hour * 24 * 7,
+		Environment:        environment,
+		InstanceID:         getEnv("INSTANCE_ID", getDefaultInstanceID()),
+		Port:               getEnv("PORT", "8080"),
+		MetricsPort:        getEnv("METRICS_PORT", "9090"),
+		RedisAddr:          getRedisAddr(),
+		RedisPassword:      getEnv("REDIS_PASSWORD", ""),
+		RedisDB:            getEnvInt("REDIS_DB", 0),
+		DBDriver:           getEnv("DB_DRIVER", "postgres"),
+		DBConnString:       getDBConnString(),
+		SQLitePath:         getEnv("SQLITE_PATH", "meroshare.db"),
+		DBAutoMigrate:      getEnvBool("DB_AUTO_MIGRATE", true),
+		AccessSecret:       getEnv("ACCESS_SECRET", "your_access_secret"),
+		RefreshSecret:      getEnv("REFRESH_SECRET", "your_refresh_secret"),
+		TokenExpiry:        time.Minute * 15,
+		RefreshExpiry:      time.Hour * 24 * 7,
+		ApplyMaxAttempts:   getEnvInt("APPLY_MAX_ATTEMPTS", 3),
+		ApplyLockTTL:       getEnvDurationAtLeast("APPLY_LOCK_TTL", 10*time.Minute, time.Second),
+		LeaderLeaseTTL:     getEnvDurationAtLeast("LEADER_LEASE_TTL", 15*time.Second, time.Second),
+		WorkerConcurrency:  getEnvInt("WORKER_CONCURRENCY", 2),
+		JobTimeout:         getEnvDuration("JOB_TIMEOUT", 30*time.Minute),
+		JobMaxAttempts:     getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		JobRetention:       getEnvDuration("JOB_RETENTION", 24*time.Hour),
+		AccountRetention:   getEnvDuration("ACCOUNT_RETENTION", 30*24*time.Hour),
+		ShutdownTimeout:    getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		ApplySchedule:      getEnv("APPLY_SCHEDULE", "0 0 * * *"),
+		CloseDayRunTime:    getEnv("CLOSE_DAY_RUN_TIME", "10:00"),
+		PurgeSchedule:      getEnv("PURGE_SCHEDULE", "30 3 * * *"),
+		DryRun:             getEnvBool("DRY_RUN", false),
+		MeroShareBaseURL:   strings.TrimSuffix(getEnv("MEROSHARE_BASE_URL", "https://webbackend.cdsc.com.np/api/meroShare"), "/"),
+		ReadyCheckCDSC:     getEnvBool("READY_CHECK_CDSC", false),
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "meroshare-bot"),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		OTLPEndpoint:       getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		Logging: logs.Options{
+			Level:        getEnv("LOG_LEVEL", logLevel),
+			Format:       getEnv("LOG_FORMAT", logFormat),
+			Output:       getEnv("LOG_OUTPUT", "stdout"),
+			MaxSizeMB:    getEnvInt("LOG_MAX_SIZE_MB", 100),
+			MaxBackups:   getEnvInt("LOG_MAX_BACKUPS", 5),
+			MaxAgeDays:   getEnvInt("LOG_MAX_AGE_DAYS", 30),
+			ModuleLevels: getEnvMap("LOG_MODULE_LEVELS"),
+		},
 	}
 }
 
@@ -54,6 +119,14 @@ func getDBConnString() string {
 	)
 }
 
+func getDefaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "meroshare-bot"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func getRedisAddr() string {
 	return fmt.Sprintf(
 		"%s:%s",
@@ -77,3 +150,53 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvMap parses a comma-separated list of key=value pairs, e.g. "gorm=warn,cdsc=debug".
+func getEnvMap(key string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(name) != "" {
+			values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDurationAtLeast is getEnvDuration for durations that must not be shorter than minimum, such as
+// lease TTLs that are renewed at a fraction of their length. Shorter values fall back to the default.
+func getEnvDurationAtLeast(key string, defaultValue, minimum time.Duration) time.Duration {
+	duration := getEnvDuration(key, defaultValue)
+	if duration < minimum {
+		logs.Warn("Duration is too short, using the default", map[string]any{"key": key, "value": duration, "minimum": minimum, "default": defaultValue})
+		return defaultValue
+	}
+	return duration
+}
@@ -1,52 +1,77 @@
 package database
 
 import (
-	"log"
-	"os"
-	"time"
+	"fmt"
 
-	"github.com/asrma7/meroshare-bot/internal/models"
 	"github.com/asrma7/meroshare-bot/pkg/config"
 	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// ConnectDB opens the database and makes sure its schema matches this binary: pending migrations
+// are applied when DB_AUTO_MIGRATE is on, and a schema that is ahead of the binary is refused.
 func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
-	logLevel := logger.Info
-	ignoreRNF := false
-	if cfg.Environment == "prod" {
-		logLevel = logger.Warn
-		ignoreRNF = true
-	}
-
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
-		logger.Config{
-			SlowThreshold:             time.Second,
-			LogLevel:                  logLevel,
-			IgnoreRecordNotFoundError: ignoreRNF,
-			Colorful:                  false,
-		},
-	)
-
-	db, err := gorm.Open(postgres.Open(cfg.DBConnString), &gorm.Config{
-		Logger: newLogger,
-	})
+	db, err := Open(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(
-		&models.User{},
-		&models.Account{},
-		&models.AppliedShare{},
-		&models.AppliedShareError{},
-	); err != nil {
+	if err := ensureSchema(db, cfg); err != nil {
 		return nil, err
 	}
 
-	logs.Info("Database connection established", nil)
+	logs.Info("Database connection established", map[string]any{"driver": cfg.DBDriver})
 	return db, nil
 }
+
+// Open connects to the database without touching the schema.
+func Open(cfg *config.Config) (*gorm.DB, error) {
+	dialector, err := openDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return gorm.Open(dialector, &gorm.Config{
+		Logger: newGormLogger(),
+	})
+}
+
+func ensureSchema(db *gorm.DB, cfg *config.Config) error {
+	migrator, err := NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		return err
+	}
+
+	current, err := migrator.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	latest := migrator.LatestVersion()
+	if current > latest {
+		return fmt.Errorf("database schema is at version %d but this binary only knows up to %d; upgrade the binary before starting it", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	if !cfg.DBAutoMigrate {
+		return fmt.Errorf("database schema is at version %d but this binary needs %d; run `migrate up` first", current, latest)
+	}
+	_, err = migrator.Up()
+	return err
+}
+
+func openDialector(cfg *config.Config) (gorm.Dialector, error) {
+	switch cfg.DBDriver {
+	case "", "postgres":
+		return postgres.Open(cfg.DBConnString), nil
+	case "sqlite":
+		// WAL and a busy timeout let the scheduler write while API requests read; immediate
+		// transactions take the write lock up front instead of failing on upgrade.
+		dsn := cfg.SQLitePath + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", cfg.DBDriver)
+}
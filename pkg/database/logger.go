@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is how long a query may take before it is logged as a warning.
+const slowQueryThreshold = time.Second
+
+var gormLog = logs.Module("gorm")
+
+// gormLogger writes GORM's output through pkg/logs under the "gorm" module, with the request's
+// correlation ID. Queries are logged at debug level, slow ones as warnings and failed ones as
+// errors. Query arguments are never logged, since they include account credentials.
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+func newGormLogger() logger.Interface {
+	return &gormLogger{level: logger.Warn}
+}
+
+// LogMode is how GORM changes the level, e.g. db.Debug() asks for logger.Info, which logs that
+// session's queries at info level.
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{level: level}
+}
+
+func (l *gormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= logger.Info {
+		gormLog.InfoContext(ctx, fmt.Sprintf(message, args...), nil)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		gormLog.WarnContext(ctx, fmt.Sprintf(message, args...), nil)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= logger.Error {
+		gormLog.ErrorContext(ctx, fmt.Sprintf(message, args...), nil)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	query := func() map[string]interface{} {
+		sql, rows := fc()
+		return map[string]interface{}{"sql": sql, "rows": rows, "duration_ms": elapsed.Milliseconds()}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		fields := query()
+		fields["error"] = err.Error()
+		gormLog.ErrorContext(ctx, "Query failed", fields)
+	case elapsed > slowQueryThreshold:
+		gormLog.WarnContext(ctx, "Slow query", query())
+	case l.level >= logger.Info:
+		gormLog.InfoContext(ctx, "Query", query())
+	case gormLog.Enabled(logrus.DebugLevel):
+		gormLog.DebugContext(ctx, "Query", query())
+	}
+}
+
+// ParamsFilter keeps query arguments out of the SQL GORM renders for Trace.
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+)
+
+func TestGormLoggerOmitsQueryArguments(t *testing.T) {
+	if err := logs.Configure(logs.Options{Level: "info", ModuleLevels: map[string]string{"gorm": "debug"}}); err != nil {
+		t.Fatalf("failed to configure logger: %v", err)
+	}
+	var buf bytes.Buffer
+	logs.Logger.SetOutput(&buf)
+	t.Cleanup(func() {
+		logs.InitLogger()
+		logs.Logger.SetOutput(io.Discard)
+	})
+
+	cfg := testConfig(t)
+	db := openTestDB(t, cfg)
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ctx := logs.WithCorrelationID(context.Background(), "req-gorm")
+	db.WithContext(ctx).Where("username = ?", "very-secret-name").First(&models.User{})
+
+	out := buf.String()
+	if strings.Contains(out, "very-secret-name") {
+		t.Fatalf("query arguments were logged:\n%s", out)
+	}
+	if !strings.Contains(out, "req-gorm") || !strings.Contains(out, `"module":"gorm"`) {
+		t.Fatalf("expected the query to be logged under the gorm module with its correlation ID, got:\n%s", out)
+	}
+}
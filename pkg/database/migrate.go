@@ -0,0 +1,298 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator interface {
+	Up() (int, error)
+	Down(steps int) (int, error)
+	Status() ([]MigrationStatus, error)
+	CurrentVersion() (int, error)
+	LatestVersion() int
+}
+
+type migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations for the given driver ("postgres" or "sqlite").
+func NewMigrator(db *gorm.DB, driver string) (Migrator, error) {
+	if driver == "" {
+		driver = "postgres"
+	}
+	migrations, err := loadMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		applied_at timestamp NOT NULL
+	)`).Error; err != nil {
+		return nil, err
+	}
+	return &migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func appliedMigrations(db *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// migrationLockID identifies the Postgres advisory lock held while migrating.
+const migrationLockID = 7340289
+
+// Up applies every pending migration in order and returns how many ran. Replicas starting at the
+// same time take turns, and each skips the migrations another has applied in the meantime.
+func (m *migrator) Up() (int, error) {
+	count := 0
+	err := m.locked(func(db *gorm.DB) error {
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			ran := false
+			err := db.Transaction(func(tx *gorm.DB) error {
+				// SQLite has no advisory lock, but its immediate transactions let one writer in at a time.
+				var recorded int64
+				if err := tx.Model(&schemaMigration{}).Where("version = ?", migration.Version).Count(&recorded).Error; err != nil || recorded > 0 {
+					return err
+				}
+				if err := execScript(tx, migration.Up); err != nil {
+					return err
+				}
+				ran = true
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			if ran {
+				logs.Info("Applied migration", map[string]any{"version": migration.Version, "name": migration.Name})
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverts the most recently applied migrations, at most steps of them.
+func (m *migrator) Down(steps int) (int, error) {
+	count := 0
+	err := m.locked(func(db *gorm.DB) error {
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := execScript(tx, migration.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			logs.Info("Reverted migration", map[string]any{"version": migration.Version, "name": migration.Name})
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// locked runs fn on a single connection holding the migration lock on Postgres.
+func (m *migrator) locked(fn func(db *gorm.DB) error) error {
+	if m.db.Dialector.Name() != "postgres" {
+		return fn(m.db)
+	}
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		return fn(conn)
+	})
+}
+
+// Status lists every known migration, including versions recorded in the database that this
+// binary does not know about.
+func (m *migrator) Status() ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+func (m *migrator) CurrentVersion() (int, error) {
+	var version int
+	err := m.db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+func (m *migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// execScript runs a migration one statement at a time; not every driver accepts several
+// statements in a single Exec. A statement ends at a line ending in ";", except inside a $$-quoted
+// function body or a trigger's BEGIN ... END block.
+func execScript(tx *gorm.DB, script string) error {
+	var statement []string
+	inQuote, inBlock := false, false
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		statement = append(statement, line)
+		if strings.Count(line, "$$")%2 == 1 {
+			inQuote = !inQuote
+		}
+		if !inQuote && strings.EqualFold(trimmed, "BEGIN") {
+			inBlock = true
+		}
+		if !inQuote && inBlock && strings.EqualFold(trimmed, "END;") {
+			inBlock = false
+		}
+		if inQuote || inBlock || !strings.HasSuffix(trimmed, ";") {
+			continue
+		}
+		if err := execStatement(tx, statement); err != nil {
+			return err
+		}
+		statement = nil
+	}
+	if len(statement) > 0 {
+		return execStatement(tx, statement)
+	}
+	return nil
+}
+
+func execStatement(tx *gorm.DB, lines []string) error {
+	return tx.Exec(strings.TrimSuffix(strings.TrimSpace(strings.Join(lines, "\n")), ";")).Error
+}
@@ -0,0 +1,296 @@
+package database
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/internal/models"
+	"github.com/asrma7/meroshare-bot/pkg/config"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestMain(m *testing.M) {
+	logs.InitLogger()
+	logs.Logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// testConfig points at a fresh SQLite file, or at TEST_DATABASE_URL when it is set. The Postgres
+// database is reset to an empty schema, so never point it at a database you care about, and run
+// `go test -p 1 ./...` so this package and the integration suite don't share it concurrently.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := &config.Config{
+		Environment: "prod",
+		DBDriver:    "sqlite",
+		SQLitePath:  filepath.Join(t.TempDir(), "meroshare.db"),
+	}
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		cfg.DBDriver = "postgres"
+		cfg.DBConnString = dsn
+
+		db := openTestDB(t, cfg)
+		for _, statement := range []string{"DROP SCHEMA public CASCADE", "CREATE SCHEMA public"} {
+			if err := db.Exec(statement).Error; err != nil {
+				t.Fatalf("failed to reset schema: %v", err)
+			}
+		}
+	}
+	return cfg
+}
+
+func openTestDB(t *testing.T, cfg *config.Config) *gorm.DB {
+	t.Helper()
+
+	db, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestMigrationsUpAndDown(t *testing.T) {
+	cfg := testConfig(t)
+	db := openTestDB(t, cfg)
+
+	migrator, err := NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if applied != migrator.LatestVersion() {
+		t.Fatalf("up: expected %d migrations, applied %d", migrator.LatestVersion(), applied)
+	}
+	if applied, _ := migrator.Up(); applied != 0 {
+		t.Fatalf("second up: expected nothing to apply, applied %d", applied)
+	}
+
+	checkModelColumns(t, db)
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Errorf("status: migration %d_%s is still pending", status.Version, status.Name)
+		}
+	}
+
+	reverted, err := migrator.Down(len(statuses))
+	if err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if reverted != len(statuses) || db.Migrator().HasTable(&models.User{}) {
+		t.Fatalf("down: expected every migration reverted, reverted %d", reverted)
+	}
+	if version, _ := migrator.CurrentVersion(); version != 0 {
+		t.Fatalf("down: expected version 0, got %d", version)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("up after down: %v", err)
+	}
+}
+
+func TestConcurrentMigrationsApplyEachOnce(t *testing.T) {
+	cfg := testConfig(t)
+
+	var wg sync.WaitGroup
+	applied := make([]int, 3)
+	errs := make([]error, len(applied))
+	latest := 0
+	for i := range applied {
+		migrator, err := NewMigrator(openTestDB(t, cfg), cfg.DBDriver)
+		if err != nil {
+			t.Fatalf("failed to load migrations: %v", err)
+		}
+		latest = migrator.LatestVersion()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied[i], errs[i] = migrator.Up()
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range applied {
+		if errs[i] != nil {
+			t.Fatalf("up %d: %v", i, errs[i])
+		}
+		total += applied[i]
+	}
+	if total != latest {
+		t.Fatalf("expected %d migrations applied once each, applied %d in total", latest, total)
+	}
+}
+
+func TestConnectDBChecksSchemaVersion(t *testing.T) {
+	cfg := testConfig(t)
+
+	_, err := ConnectDB(cfg)
+	if err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Fatalf("expected pending migrations to be refused without auto migrate, got %v", err)
+	}
+
+	cfg.DBAutoMigrate = true
+	db, err := ConnectDB(cfg)
+	if err != nil {
+		t.Fatalf("connect with auto migrate: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	if err := db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, CURRENT_TIMESTAMP)", 9999, "from_the_future").Error; err != nil {
+		t.Fatalf("failed to record future migration: %v", err)
+	}
+	if _, err := ConnectDB(cfg); err == nil || !strings.Contains(err.Error(), "upgrade the binary") {
+		t.Fatalf("expected a schema ahead of the binary to be refused, got %v", err)
+	}
+}
+
+func TestUniqueAppliedShareMigrationRemovesDuplicates(t *testing.T) {
+	cfg := testConfig(t)
+	db := openTestDB(t, cfg)
+
+	migrator, err := NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	// Roll back to the schema from before the unique index existed.
+	for version, _ := migrator.CurrentVersion(); version > 3; version, _ = migrator.CurrentVersion() {
+		if _, err := migrator.Down(1); err != nil {
+			t.Fatalf("down: %v", err)
+		}
+	}
+
+	account, user := uuid.New(), uuid.New()
+	insert := func(status string, updatedAt time.Time) uuid.UUID {
+		share := &models.AppliedShare{
+			UserID: user, AccountID: account, CompanyShareID: 700, CompanyName: "Mock", Scrip: "MOCK",
+			AppliedKitta: "10", ShareGroupName: "Ordinary Shares", ShareTypeName: "IPO", SubGroup: "For General Public",
+			Status: status, UpdatedAt: updatedAt,
+		}
+		if err := db.Create(share).Error; err != nil {
+			t.Fatalf("failed to insert applied share: %v", err)
+		}
+		if err := db.Create(&models.AppliedShareError{UserID: user, AccountID: account, AppliedShareID: share.ID, Message: status}).Error; err != nil {
+			t.Fatalf("failed to insert applied share error: %v", err)
+		}
+		return share.ID
+	}
+	now := time.Now()
+	insert("failed", now)
+	applied := insert("applied", now.Add(-time.Hour))
+	insert("failed", now.Add(-2*time.Hour))
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("up with duplicates: %v", err)
+	}
+
+	var shares []models.AppliedShare
+	db.Find(&shares)
+	if len(shares) != 1 || shares[0].ID != applied {
+		t.Fatalf("expected only the applied row to survive, got %+v", shares)
+	}
+	var orphaned int64
+	db.Model(&models.AppliedShareError{}).Where("applied_share_id <> ?", applied).Count(&orphaned)
+	if orphaned != 0 {
+		t.Fatalf("expected every attempt to move to the surviving row, %d did not", orphaned)
+	}
+}
+
+// Databases created by AutoMigrate before versioned migrations have the baseline tables but no
+// schema_migrations rows; Up has to bring them to the current schema without losing their rows.
+func TestMigrationsUpgradeBaselineSchema(t *testing.T) {
+	cfg := testConfig(t)
+	db := openTestDB(t, cfg)
+
+	migrations, err := loadMigrations(cfg.DBDriver)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := execScript(db, migrations[0].Up); err != nil {
+		t.Fatalf("failed to create baseline schema: %v", err)
+	}
+	userID, accountID, shareID := uuid.New(), uuid.New(), uuid.New()
+	for _, statement := range []struct {
+		sql  string
+		args []any
+	}{
+		{"INSERT INTO users (id, username, email, password, first_name, last_name) VALUES (?, 'alice', 'alice@example.com', 'x', 'Alice', 'A')", []any{userID}},
+		{`INSERT INTO accounts (id, user_id, name, email, contact, client_id, username, password, bank_id, crn_number, transaction_pin,
+			account_type_id, preferred_kitta, demat, bo_id, account_number, customer_id, account_branch_id, dmat_expiry_date, expired_date, password_expiry_date)
+			VALUES (?, ?, 'Alice', 'alice@example.com', '98', 101, 'alice-meroshare', 'x', '54', 'CRN', '1234', 1, '10', 'D', 'B', 'N', 1, 1, '', ?, ?)`,
+			[]any{accountID, userID, time.Now(), time.Now()}},
+		{`INSERT INTO applied_shares (id, user_id, account_id, company_name, company_share_id, scrip, applied_kitta, share_group_name, share_type_name, sub_group)
+			VALUES (?, ?, ?, 'Mock', 700, 'MOCK', '10', 'Ordinary Shares', 'IPO', 'For General Public')`, []any{shareID, userID, accountID}},
+		{"INSERT INTO applied_share_errors (id, user_id, account_id, applied_share_id, message) VALUES (?, ?, ?, ?, 'failed')", []any{uuid.New(), userID, accountID, shareID}},
+	} {
+		if err := db.Exec(statement.sql, statement.args...).Error; err != nil {
+			t.Fatalf("failed to insert baseline row: %v", err)
+		}
+	}
+
+	migrator, err := NewMigrator(db, cfg.DBDriver)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if applied, err := migrator.Up(); err != nil || applied != migrator.LatestVersion() {
+		t.Fatalf("up: expected %d migrations, applied %d: %v", migrator.LatestVersion(), applied, err)
+	}
+	checkModelColumns(t, db)
+
+	var account models.Account
+	if err := db.First(&account, "id = ?", accountID).Error; err != nil {
+		t.Fatalf("failed to load baseline account: %v", err)
+	}
+	account.ApplyMutualFunds = true
+	if err := db.Save(&account).Error; err != nil {
+		t.Fatalf("failed to update baseline account: %v", err)
+	}
+	var share models.AppliedShare
+	if err := db.First(&share, "id = ?", shareID).Error; err != nil || share.Attempts != 1 {
+		t.Fatalf("expected the baseline applied share to default to one attempt, got %+v: %v", share, err)
+	}
+	if err := db.Create(&models.AppliedShareError{UserID: userID, AccountID: accountID, AppliedShareID: shareID, Message: "again", Attempt: 2}).Error; err != nil {
+		t.Fatalf("failed to record an attempt: %v", err)
+	}
+}
+
+// checkModelColumns fails the test for every model field without a column, which means the
+// migrations have drifted from the models.
+func checkModelColumns(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	for _, model := range []any{&models.User{}, &models.Account{}, &models.AppliedShare{}, &models.AppliedShareError{}, &models.Issue{}, &models.AuditEvent{}, &models.AccountGrant{}} {
+		s, err := schema.Parse(model, &sync.Map{}, db.NamingStrategy)
+		if err != nil {
+			t.Fatalf("failed to parse %T: %v", model, err)
+		}
+		for _, field := range s.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("%s.%s has no migration", s.Table, field.DBName)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+-- Columns added for right shares, mutual funds and debentures, reserved quotas, bank balances and
+-- retries.
+ALTER TABLE accounts ADD COLUMN right_share_fraction decimal DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN apply_mutual_funds boolean DEFAULT false;
+ALTER TABLE accounts ADD COLUMN mutual_fund_units bigint DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN mutual_fund_max_amount decimal DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN apply_debentures boolean DEFAULT false;
+ALTER TABLE accounts ADD COLUMN debenture_units bigint DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN debenture_max_amount decimal DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN district varchar(50) DEFAULT '';
+ALTER TABLE accounts ADD COLUMN foreign_employment boolean DEFAULT false;
+ALTER TABLE accounts ADD COLUMN available_balance decimal;
+ALTER TABLE applied_shares ADD COLUMN kitta_source varchar(30) DEFAULT 'preferred';
+ALTER TABLE applied_shares ADD COLUMN eligible_kitta bigint DEFAULT 0;
+ALTER TABLE applied_shares ADD COLUMN price_per_unit decimal DEFAULT 0;
+ALTER TABLE applied_shares ADD COLUMN amount decimal DEFAULT 0;
+ALTER TABLE applied_shares ADD COLUMN remark text DEFAULT '';
+ALTER TABLE applied_shares ADD COLUMN attempts bigint DEFAULT 1;
+ALTER TABLE applied_shares ADD COLUMN permanent boolean DEFAULT false;
+ALTER TABLE applied_share_errors ADD COLUMN attempt bigint DEFAULT 1;
+ALTER TABLE applied_share_errors ADD COLUMN permanent boolean DEFAULT false;
+ALTER TABLE applied_shares ALTER COLUMN status TYPE varchar(30);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS account_grants (
+    id uuid PRIMARY KEY,
+    account_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role varchar(20) NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    invited_by uuid NOT NULL,
+    accepted_at timestamptz,
+    created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_grants_account_user ON account_grants (account_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_account_grants_user_id ON account_grants (user_id);
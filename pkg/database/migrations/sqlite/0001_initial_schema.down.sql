@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS applied_share_errors;
+DROP TABLE IF EXISTS applied_shares;
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,77 @@
+-- The schema AutoMigrate created before versioned migrations, so existing databases only record
+-- the version. Columns added since then come in later migrations.
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY,
+    username varchar(50) NOT NULL,
+    email text NOT NULL,
+    password text NOT NULL,
+    first_name varchar(100) NOT NULL,
+    last_name varchar(100) NOT NULL,
+    created_at datetime DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    email text NOT NULL,
+    contact text NOT NULL,
+    client_id integer NOT NULL,
+    username varchar(50) NOT NULL,
+    password text NOT NULL,
+    bank_id text NOT NULL,
+    crn_number text NOT NULL,
+    transaction_pin text NOT NULL,
+    account_type_id integer NOT NULL,
+    preferred_kitta text NOT NULL,
+    demat text NOT NULL,
+    bo_id text NOT NULL,
+    account_number text NOT NULL,
+    customer_id integer NOT NULL,
+    account_branch_id integer NOT NULL,
+    dmat_expiry_date varchar(50) NOT NULL,
+    expired_date datetime NOT NULL,
+    password_expiry_date datetime NOT NULL,
+    created_at datetime DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+    status varchar(20) DEFAULT 'active',
+    deleted_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_username ON accounts (username);
+CREATE INDEX IF NOT EXISTS idx_accounts_deleted_at ON accounts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS applied_shares (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    company_name text NOT NULL,
+    company_share_id integer NOT NULL,
+    scrip text NOT NULL,
+    applied_kitta text NOT NULL,
+    share_group_name text NOT NULL,
+    share_type_name text NOT NULL,
+    sub_group text NOT NULL,
+    status varchar(20) DEFAULT 'applied',
+    created_at datetime DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_applied_shares_user_id ON applied_shares (user_id);
+CREATE INDEX IF NOT EXISTS idx_applied_shares_account_id ON applied_shares (account_id);
+
+CREATE TABLE IF NOT EXISTS applied_share_errors (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    applied_share_id uuid NOT NULL,
+    message text NOT NULL,
+    seen numeric DEFAULT false,
+    created_at datetime DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_applied_share_errors_user_id ON applied_share_errors (user_id);
+CREATE INDEX IF NOT EXISTS idx_applied_share_errors_account_id ON applied_share_errors (account_id);
+CREATE INDEX IF NOT EXISTS idx_applied_share_errors_applied_share_id ON applied_share_errors (applied_share_id);
@@ -0,0 +1,19 @@
+ALTER TABLE accounts DROP COLUMN available_balance;
+ALTER TABLE accounts DROP COLUMN foreign_employment;
+ALTER TABLE accounts DROP COLUMN district;
+ALTER TABLE accounts DROP COLUMN debenture_max_amount;
+ALTER TABLE accounts DROP COLUMN debenture_units;
+ALTER TABLE accounts DROP COLUMN apply_debentures;
+ALTER TABLE accounts DROP COLUMN mutual_fund_max_amount;
+ALTER TABLE accounts DROP COLUMN mutual_fund_units;
+ALTER TABLE accounts DROP COLUMN apply_mutual_funds;
+ALTER TABLE accounts DROP COLUMN right_share_fraction;
+ALTER TABLE applied_shares DROP COLUMN permanent;
+ALTER TABLE applied_shares DROP COLUMN attempts;
+ALTER TABLE applied_shares DROP COLUMN remark;
+ALTER TABLE applied_shares DROP COLUMN amount;
+ALTER TABLE applied_shares DROP COLUMN price_per_unit;
+ALTER TABLE applied_shares DROP COLUMN eligible_kitta;
+ALTER TABLE applied_shares DROP COLUMN kitta_source;
+ALTER TABLE applied_share_errors DROP COLUMN permanent;
+ALTER TABLE applied_share_errors DROP COLUMN attempt;
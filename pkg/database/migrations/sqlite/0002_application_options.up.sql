@@ -0,0 +1,21 @@
+-- Columns added for right shares, mutual funds and debentures, reserved quotas, bank balances and
+-- retries.
+ALTER TABLE accounts ADD COLUMN right_share_fraction real DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN apply_mutual_funds numeric DEFAULT false;
+ALTER TABLE accounts ADD COLUMN mutual_fund_units integer DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN mutual_fund_max_amount real DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN apply_debentures numeric DEFAULT false;
+ALTER TABLE accounts ADD COLUMN debenture_units integer DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN debenture_max_amount real DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN district varchar(50) DEFAULT '';
+ALTER TABLE accounts ADD COLUMN foreign_employment numeric DEFAULT false;
+ALTER TABLE accounts ADD COLUMN available_balance real;
+ALTER TABLE applied_shares ADD COLUMN kitta_source varchar(30) DEFAULT 'preferred';
+ALTER TABLE applied_shares ADD COLUMN eligible_kitta integer DEFAULT 0;
+ALTER TABLE applied_shares ADD COLUMN price_per_unit real DEFAULT 0;
+ALTER TABLE applied_shares ADD COLUMN amount real DEFAULT 0;
+ALTER TABLE applied_shares ADD COLUMN remark text DEFAULT '';
+ALTER TABLE applied_shares ADD COLUMN attempts integer DEFAULT 1;
+ALTER TABLE applied_shares ADD COLUMN permanent numeric DEFAULT false;
+ALTER TABLE applied_share_errors ADD COLUMN attempt integer DEFAULT 1;
+ALTER TABLE applied_share_errors ADD COLUMN permanent numeric DEFAULT false;
@@ -0,0 +1,20 @@
+CREATE TABLE issues (
+    id uuid PRIMARY KEY,
+    company_share_id integer NOT NULL,
+    company_name text NOT NULL,
+    scrip text NOT NULL,
+    share_type_name text NOT NULL,
+    share_group_name text NOT NULL,
+    sub_group text NOT NULL,
+    issue_open_date datetime,
+    issue_close_date datetime,
+    status varchar(20) DEFAULT 'open',
+    first_seen_at datetime DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at datetime DEFAULT CURRENT_TIMESTAMP,
+    created_at datetime DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_issues_company_share_id ON issues (company_share_id);
+CREATE INDEX idx_issues_scrip ON issues (scrip);
+CREATE INDEX idx_issues_issue_close_date ON issues (issue_close_date);
+CREATE INDEX idx_issues_status ON issues (status);
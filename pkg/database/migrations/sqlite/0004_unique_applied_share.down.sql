@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_applied_shares_account_share;
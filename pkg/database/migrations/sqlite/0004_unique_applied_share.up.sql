@@ -0,0 +1,27 @@
+-- Keep one row per account and issue, preferring a successful application and then the most
+-- recently updated row, and move the attempt history of the dropped rows onto it.
+UPDATE applied_share_errors
+SET applied_share_id = (
+    SELECT keep.id
+    FROM applied_shares dup
+    JOIN applied_shares keep
+        ON keep.account_id = dup.account_id AND keep.company_share_id = dup.company_share_id
+    WHERE dup.id = applied_share_errors.applied_share_id
+    ORDER BY CASE WHEN keep.status = 'applied' THEN 0 ELSE 1 END, keep.updated_at DESC, keep.id
+    LIMIT 1
+)
+WHERE applied_share_id IN (SELECT id FROM applied_shares);
+
+DELETE FROM applied_shares
+WHERE id NOT IN (
+    SELECT (
+        SELECT keep.id
+        FROM applied_shares keep
+        WHERE keep.account_id = a.account_id AND keep.company_share_id = a.company_share_id
+        ORDER BY CASE WHEN keep.status = 'applied' THEN 0 ELSE 1 END, keep.updated_at DESC, keep.id
+        LIMIT 1
+    )
+    FROM applied_shares a
+);
+
+CREATE UNIQUE INDEX idx_applied_shares_account_share ON applied_shares (account_id, company_share_id);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_accounts_username;
+-- The full index allows one row per username, so keep the live account, or else the most recently
+-- deleted one, and drop the rest.
+DELETE FROM accounts
+WHERE deleted_at IS NOT NULL
+    AND EXISTS (
+        SELECT 1 FROM accounts other
+        WHERE other.username = accounts.username
+            AND other.id <> accounts.id
+            AND (other.deleted_at IS NULL OR other.deleted_at > accounts.deleted_at
+                OR (other.deleted_at = accounts.deleted_at AND other.id > accounts.id))
+    );
+CREATE UNIQUE INDEX idx_accounts_username ON accounts (username);
@@ -0,0 +1,4 @@
+-- Deleted accounts keep their row until they are purged, so only live accounts need a unique
+-- username; otherwise a deleted account could never be added again.
+DROP INDEX IF EXISTS idx_accounts_username;
+CREATE UNIQUE INDEX idx_accounts_username ON accounts (username) WHERE deleted_at IS NULL;
@@ -0,0 +1,77 @@
+package logs
+
+import (
+	"context"
+)
+
+type fieldsKey struct{}
+
+const (
+	correlationIDField = "correlation_id"
+	userIDField        = "user_id"
+)
+
+// WithFields returns a context whose log entries, written through the *Context functions, carry
+// fields in addition to their own.
+func WithFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	merged := make(map[string]interface{}, len(fields))
+	for key, value := range contextFields(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithCorrelationID tags every entry logged under ctx with id, so the lines written for one API
+// request or job, including its calls to MeroShare, can be found together.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return WithFields(ctx, map[string]interface{}{correlationIDField: id})
+}
+
+// WithUserID tags every entry logged under ctx with the ID of the user the work is done for.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return WithFields(ctx, map[string]interface{}{userIDField: userID})
+}
+
+func CorrelationID(ctx context.Context) string {
+	id, _ := contextFields(ctx)[correlationIDField].(string)
+	return id
+}
+
+func contextFields(ctx context.Context) map[string]interface{} {
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+	return fields
+}
+
+func entry(ctx context.Context, fields map[string]interface{}) map[string]interface{} {
+	base := contextFields(ctx)
+	if len(base) == 0 {
+		return fields
+	}
+	merged := make(map[string]interface{}, len(base)+len(fields))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged
+}
+
+func InfoContext(ctx context.Context, message string, fields map[string]interface{}) {
+	root.InfoContext(ctx, message, fields)
+}
+
+func DebugContext(ctx context.Context, message string, fields map[string]interface{}) {
+	root.DebugContext(ctx, message, fields)
+}
+
+func WarnContext(ctx context.Context, message string, fields map[string]interface{}) {
+	root.WarnContext(ctx, message, fields)
+}
+
+func ErrorContext(ctx context.Context, message string, fields map[string]interface{}) {
+	root.ErrorContext(ctx, message, fields)
+}
@@ -1,34 +1,135 @@
 package logs
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var Logger *logrus.Logger
 
+// Options configures the logger. Levels are logrus level names; ModuleLevels overrides Level for
+// the modules named, e.g. {"gorm": "warn", "cdsc": "debug"}.
+type Options struct {
+	Level        string
+	Format       string
+	Output       string
+	MaxSizeMB    int
+	MaxBackups   int
+	MaxAgeDays   int
+	ModuleLevels map[string]string
+}
+
+var (
+	defaultLevel = logrus.DebugLevel
+	moduleLevels = map[string]logrus.Level{}
+	root         = &ModuleLogger{}
+)
+
+// InitLogger sets up a debug-level JSON logger on stdout, used until Configure replaces it with
+// the configured one.
 func InitLogger() {
 	Logger = logrus.New()
 	Logger.SetLevel(logrus.DebugLevel)
-	Logger.SetFormatter(&logrus.JSONFormatter{
-		PrettyPrint: true,
-	})
+	Logger.SetFormatter(&logrus.JSONFormatter{})
 	Logger.SetOutput(os.Stdout)
+	defaultLevel = logrus.DebugLevel
+	moduleLevels = map[string]logrus.Level{}
+}
+
+// Configure applies opts to the logger. It is meant to run once at startup, before the logger is
+// shared between goroutines. Format is json, text or logfmt; Output is stdout, stderr or the path
+// of a file that is rotated once it reaches MaxSizeMB.
+func Configure(opts Options) error {
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+	modules := make(map[string]logrus.Level, len(opts.ModuleLevels))
+	for module, name := range opts.ModuleLevels {
+		if modules[module], err = parseLevel(name); err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+	}
+
+	formatter, err := newFormatter(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer
+	switch opts.Output {
+	case "", "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	default:
+		output = &lumberjack.Logger{
+			Filename:   opts.Output,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+		}
+	}
+
+	// logrus filters on a single level, so it is set to the most verbose one in use and each
+	// entry is checked against its own module's level before it is written.
+	loggerLevel := level
+	for _, moduleLevel := range modules {
+		if moduleLevel > loggerLevel {
+			loggerLevel = moduleLevel
+		}
+	}
+	Logger.SetLevel(loggerLevel)
+	Logger.SetFormatter(formatter)
+	Logger.SetOutput(output)
+	defaultLevel = level
+	moduleLevels = modules
+	return nil
+}
+
+func parseLevel(name string) (logrus.Level, error) {
+	if name == "" {
+		return logrus.InfoLevel, nil
+	}
+	return logrus.ParseLevel(name)
+}
+
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", "json":
+		return &logrus.JSONFormatter{}, nil
+	case "text":
+		return &logrus.TextFormatter{FullTimestamp: true, PadLevelText: true}, nil
+	case "logfmt":
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true, QuoteEmptyFields: true}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+func levelFor(module string) logrus.Level {
+	if level, ok := moduleLevels[module]; ok {
+		return level
+	}
+	return defaultLevel
 }
 
 func Info(message string, fields map[string]interface{}) {
-	Logger.WithFields(fields).Info(message)
+	root.Info(message, fields)
 }
 
 func Debug(message string, fields map[string]interface{}) {
-	Logger.WithFields(fields).Debug(message)
+	root.Debug(message, fields)
 }
 
 func Warn(message string, fields map[string]interface{}) {
-	Logger.WithFields(fields).Warn(message)
+	root.Warn(message, fields)
 }
 
 func Error(message string, fields map[string]interface{}) {
-	Logger.WithFields(fields).Error(message)
+	root.Error(message, fields)
 }
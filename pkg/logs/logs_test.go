@@ -0,0 +1,99 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func configure(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+
+	InitLogger()
+	if err := Configure(opts); err != nil {
+		t.Fatalf("failed to configure logger: %v", err)
+	}
+	var buf bytes.Buffer
+	Logger.SetOutput(&buf)
+	return &buf
+}
+
+func TestModuleLevels(t *testing.T) {
+	buf := configure(t, Options{Level: "info", ModuleLevels: map[string]string{"gorm": "debug", "cdsc": "error"}})
+
+	Debug("root debug", nil)
+	Info("root info", nil)
+	Module("gorm").Debug("gorm debug", nil)
+	Module("cdsc").Warn("cdsc warn", nil)
+	Module("cdsc").Error("cdsc error", nil)
+
+	out := buf.String()
+	for _, want := range []string{"root info", "gorm debug", "cdsc error"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q to be logged, got:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"root debug", "cdsc warn"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("expected %q to be filtered out, got:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	buf := configure(t, Options{Level: "debug", Format: "json"})
+
+	ctx := WithCorrelationID(context.Background(), "req-1")
+	ctx = WithUserID(ctx, "user-1")
+	Module("cdsc").InfoContext(ctx, "call", map[string]interface{}{"status": 200})
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON entry, got %q: %v", buf.String(), err)
+	}
+	for key, want := range map[string]interface{}{"correlation_id": "req-1", "user_id": "user-1", "module": "cdsc", "status": 200.0, "msg": "call"} {
+		if entry[key] != want {
+			t.Errorf("%s: got %v, want %v", key, entry[key], want)
+		}
+	}
+	if CorrelationID(ctx) != "req-1" {
+		t.Errorf("CorrelationID: got %q", CorrelationID(ctx))
+	}
+}
+
+func TestFormats(t *testing.T) {
+	buf := configure(t, Options{Level: "info", Format: "logfmt"})
+	Info("hello world", map[string]interface{}{"job_id": "j1"})
+	if out := buf.String(); !strings.Contains(out, `msg="hello world"`) || !strings.Contains(out, "job_id=j1") {
+		t.Fatalf("expected logfmt output, got %q", out)
+	}
+
+	InitLogger()
+	if err := Configure(Options{Format: "xml"}); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+	if err := Configure(Options{Level: "loud"}); err == nil {
+		t.Fatal("expected an unknown level to be rejected")
+	}
+}
+
+func TestFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.log")
+	InitLogger()
+	if err := Configure(Options{Level: "info", Output: path, MaxSizeMB: 1}); err != nil {
+		t.Fatalf("failed to configure logger: %v", err)
+	}
+	Info("to file", nil)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "to file") {
+		t.Fatalf("expected the entry in the log file, got %q", data)
+	}
+}
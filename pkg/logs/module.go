@@ -0,0 +1,66 @@
+package logs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModuleLogger writes entries tagged with a module name, filtered by that module's level.
+type ModuleLogger struct {
+	name string
+}
+
+// Module returns the logger for a part of the bot, such as "gorm" or "cdsc", whose level can be
+// set apart from the rest through Options.ModuleLevels.
+func Module(name string) *ModuleLogger {
+	return &ModuleLogger{name: name}
+}
+
+// Enabled reports whether entries at level would be written.
+func (m *ModuleLogger) Enabled(level logrus.Level) bool {
+	return level <= levelFor(m.name)
+}
+
+func (m *ModuleLogger) log(level logrus.Level, message string, fields map[string]interface{}) {
+	if !m.Enabled(level) {
+		return
+	}
+	entry := Logger.WithFields(fields)
+	if m.name != "" {
+		entry = entry.WithField("module", m.name)
+	}
+	entry.Log(level, message)
+}
+
+func (m *ModuleLogger) Info(message string, fields map[string]interface{}) {
+	m.log(logrus.InfoLevel, message, fields)
+}
+
+func (m *ModuleLogger) Debug(message string, fields map[string]interface{}) {
+	m.log(logrus.DebugLevel, message, fields)
+}
+
+func (m *ModuleLogger) Warn(message string, fields map[string]interface{}) {
+	m.log(logrus.WarnLevel, message, fields)
+}
+
+func (m *ModuleLogger) Error(message string, fields map[string]interface{}) {
+	m.log(logrus.ErrorLevel, message, fields)
+}
+
+func (m *ModuleLogger) InfoContext(ctx context.Context, message string, fields map[string]interface{}) {
+	m.log(logrus.InfoLevel, message, entry(ctx, fields))
+}
+
+func (m *ModuleLogger) DebugContext(ctx context.Context, message string, fields map[string]interface{}) {
+	m.log(logrus.DebugLevel, message, entry(ctx, fields))
+}
+
+func (m *ModuleLogger) WarnContext(ctx context.Context, message string, fields map[string]interface{}) {
+	m.log(logrus.WarnLevel, message, entry(ctx, fields))
+}
+
+func (m *ModuleLogger) ErrorContext(ctx context.Context, message string, fields map[string]interface{}) {
+	m.log(logrus.ErrorLevel, message, entry(ctx, fields))
+}
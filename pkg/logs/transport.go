@@ -0,0 +1,82 @@
+package logs
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/meroshare"
+	"github.com/sirupsen/logrus"
+)
+
+// maxLoggedBody bounds how much of a redacted body goes into one log entry.
+const maxLoggedBody = 4096
+
+var cdscLog = Module("cdsc")
+
+// cdscTransport logs every call to the MeroShare API with its bodies redacted, under the "cdsc"
+// module. Successful calls are logged at debug level, failed ones as warnings.
+type cdscTransport struct {
+	next     http.RoundTripper
+	basePath string
+}
+
+// NewCDSCTransport wraps next to log calls made to the MeroShare API at baseURL.
+func NewCDSCTransport(next http.RoundTripper, baseURL string) http.RoundTripper {
+	return &cdscTransport{next: next, basePath: meroshare.BasePath(baseURL)}
+}
+
+func (t *cdscTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !cdscLog.Enabled(logrus.WarnLevel) {
+		return t.next.RoundTrip(req)
+	}
+
+	fields := map[string]interface{}{
+		"method":          req.Method,
+		"endpoint":        meroshare.Endpoint(req, t.basePath),
+		"request_headers": meroshare.RedactHeaders(req.Header),
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			fields["request_body"] = truncate(meroshare.RedactBody(data))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields["error"] = err.Error()
+		cdscLog.WarnContext(req.Context(), "CDSC request failed", fields)
+		return resp, err
+	}
+
+	fields["status"] = resp.StatusCode
+	fields["response_headers"] = meroshare.RedactHeaders(resp.Header)
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		fields["error"] = err.Error()
+		cdscLog.WarnContext(req.Context(), "CDSC request failed", fields)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	fields["response_body"] = truncate(meroshare.RedactBody(data))
+
+	if resp.StatusCode >= 400 {
+		cdscLog.WarnContext(req.Context(), "CDSC request failed", fields)
+	} else {
+		cdscLog.DebugContext(req.Context(), "CDSC request", fields)
+	}
+	return resp, nil
+}
+
+func truncate(body string) string {
+	if len(body) <= maxLoggedBody {
+		return body
+	}
+	return body[:maxLoggedBody] + "...[truncated]"
+}
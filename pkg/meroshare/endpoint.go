@@ -0,0 +1,30 @@
+// Package meroshare holds what the bot knows about the MeroShare (CDSC) API itself, independent of
+// the services that call it.
+package meroshare
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BasePath returns the path prefix of the configured MeroShare base URL, e.g. "/api/meroShare".
+func BasePath(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// Endpoint names the API endpoint a request calls, relative to basePath, with IDs such as the
+// company share ID or DMAT number replaced by ":id", e.g. "GET /active/:id".
+func Endpoint(req *http.Request, basePath string) string {
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, basePath), "/")
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = ":id"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
@@ -0,0 +1,377 @@
+// Package mock emulates the subset of the MeroShare (CDSC) backend the bot talks to, so the apply
+// pipeline can run without the real service. Server is an http.Handler; wrap it in httptest.NewServer
+// in tests or serve it from cmd/meroshare-mock for demos, and point MEROSHARE_BASE_URL at the server URL plus BasePath.
+package mock
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scenario selects how the mock misbehaves.
+type Scenario string
+
+const (
+	ScenarioHappy                Scenario = "happy"
+	ScenarioInvalidCredentials   Scenario = "invalid_credentials"
+	ScenarioExpiredPassword      Scenario = "expired_password"
+	ScenarioWrongPIN             Scenario = "wrong_pin"
+	ScenarioApplicationInProcess Scenario = "application_in_process"
+	ScenarioApplyUnavailable     Scenario = "apply_unavailable"
+	ScenarioRightUnavailable     Scenario = "right_unavailable"
+	ScenarioBalanceUnavailable   Scenario = "balance_unavailable"
+)
+
+// BasePath is the prefix every MeroShare endpoint lives under.
+const BasePath = "/api/meroShare"
+
+type Issue struct {
+	CompanyShareID uint16  `json:"companyShareId"`
+	SubGroup       string  `json:"subGroup"`
+	Scrip          string  `json:"scrip"`
+	CompanyName    string  `json:"companyName"`
+	ShareTypeName  string  `json:"shareTypeName"`
+	ShareGroupName string  `json:"shareGroupName"`
+	StatusName     string  `json:"statusName"`
+	Action         string  `json:"action"`
+	IssueOpenDate  string  `json:"issueOpenDate"`
+	IssueCloseDate string  `json:"issueCloseDate"`
+	MinUnit        int     `json:"-"`
+	MaxUnit        int     `json:"-"`
+	MultipleOf     int     `json:"-"`
+	SharePerUnit   float64 `json:"-"`
+	EligibleKitta  int     `json:"-"`
+}
+
+// Application is an apply request the mock accepted.
+type Application struct {
+	Demat          string `json:"demat"`
+	BOID           string `json:"boid"`
+	AccountNumber  string `json:"accountNumber"`
+	AppliedKitta   string `json:"appliedKitta"`
+	CRNNumber      string `json:"crnNumber"`
+	TransactionPIN string `json:"transactionPIN"`
+	CompanyShareID string `json:"companyShareId"`
+	BankID         string `json:"bankId"`
+}
+
+type Server struct {
+	mu           sync.Mutex
+	scenario     Scenario
+	password     string
+	pin          string
+	balance      *float64
+	issues       []Issue
+	tokens       map[string]string
+	applications []Application
+}
+
+type Option func(*Server)
+
+// WithScenario sets the initial scenario; it defaults to ScenarioHappy.
+func WithScenario(scenario Scenario) Option {
+	return func(s *Server) { s.scenario = scenario }
+}
+
+// WithCredentials sets the password and transaction PIN the mock accepts. Empty values accept anything.
+func WithCredentials(password, pin string) Option {
+	return func(s *Server) {
+		s.password = password
+		s.pin = pin
+	}
+}
+
+// WithIssues replaces the default set of open issues.
+func WithIssues(issues ...Issue) Option {
+	return func(s *Server) { s.issues = issues }
+}
+
+// WithBalance makes /bank/balance/ report a balance instead of 404.
+func WithBalance(balance float64) Option {
+	return func(s *Server) { s.balance = &balance }
+}
+
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		scenario: ScenarioHappy,
+		issues:   DefaultIssues(),
+		tokens:   make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// DefaultIssues is one open ordinary IPO for the general public, closing in three days.
+func DefaultIssues() []Issue {
+	now := time.Now()
+	return []Issue{{
+		CompanyShareID: 700,
+		SubGroup:       "For General Public",
+		Scrip:          "MOCK",
+		CompanyName:    "Mock Hydropower Limited",
+		ShareTypeName:  "IPO",
+		ShareGroupName: "Ordinary Shares",
+		StatusName:     "CREATE_APPROVE",
+		IssueOpenDate:  now.AddDate(0, 0, -1).Format("Jan 2, 2006 3:04:05 PM"),
+		IssueCloseDate: now.AddDate(0, 0, 3).Format("Jan 2, 2006 3:04:05 PM"),
+		MinUnit:        10,
+		MaxUnit:        10000,
+		MultipleOf:     10,
+		SharePerUnit:   100,
+	}}
+}
+
+func (s *Server) SetScenario(scenario Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenario = scenario
+}
+
+func (s *Server) SetIssues(issues ...Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issues = issues
+}
+
+// Applications returns the applications accepted so far.
+func (s *Server) Applications() []Application {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Application(nil), s.applications...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.Path, BasePath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && path == "/auth/":
+		s.handleAuth(w, r)
+		return
+	case r.Method == http.MethodGet && path == "/healthz":
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if !s.authorized(r) {
+		writeXMLError(w, http.StatusUnauthorized, "Invalid or expired token")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && path == "/ownDetail/":
+		s.handleOwnDetail(w)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/bank/balance/"):
+		s.handleBalance(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/bank/"):
+		s.handleBank(w)
+	case r.Method == http.MethodPost && path == "/companyShare/applicableIssue/":
+		s.handleApplicableIssues(w)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/active/"):
+		s.handleIssueDetails(w, r, strings.TrimPrefix(path, "/active/"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/applicantForm/rightShare/eligibility/"):
+		s.handleRightEligibility(w, r, strings.TrimPrefix(path, "/applicantForm/rightShare/eligibility/"))
+	case r.Method == http.MethodPost && path == "/applicantForm/share/apply/":
+		s.handleApply(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ClientID string `json:"clientId"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Invalid request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scenario == ScenarioInvalidCredentials || (s.password != "" && body.Password != s.password) {
+		writeXMLError(w, http.StatusUnauthorized, "Invalid password. Please try again.")
+		return
+	}
+
+	token := uuid.NewString()
+	s.tokens[token] = body.Username
+	w.Header().Set("Authorization", token)
+	writeJSON(w, http.StatusOK, map[string]any{"statusCode": 200, "message": "Log in successful."})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.tokens[r.Header.Get("Authorization")]
+	return ok
+}
+
+func (s *Server) handleOwnDetail(w http.ResponseWriter) {
+	s.mu.Lock()
+	scenario := s.scenario
+	s.mu.Unlock()
+
+	now := time.Now()
+	passwordExpiry := now.AddDate(0, 3, 0)
+	if scenario == ScenarioExpiredPassword {
+		passwordExpiry = now.AddDate(0, 0, -1)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"boid":               "00000001",
+		"contact":            "9800000000",
+		"demat":              "1301000000000001",
+		"email":              "mock@example.com",
+		"name":               "Mock Investor",
+		"dematExpiryDate":    "2090-12-30",
+		"passwordExpiryDate": passwordExpiry,
+		"expiredDate":        now.AddDate(1, 0, 0),
+	})
+}
+
+func (s *Server) handleBank(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, []map[string]any{{
+		"accountBranchId": 1,
+		"accountNumber":   "0010000000001",
+		"accountTypeId":   1,
+		"accountTypeName": "Saving Account",
+		"branchName":      "Mock Branch",
+		"id":              100,
+	}})
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	balance := s.balance
+	scenario := s.scenario
+	s.mu.Unlock()
+
+	if scenario == ScenarioBalanceUnavailable {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "Internal server error"})
+		return
+	}
+	if balance == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"accountNumber": "0010000000001", "availableBalance": *balance})
+}
+
+func (s *Server) handleApplicableIssues(w http.ResponseWriter) {
+	s.mu.Lock()
+	issues := append([]Issue(nil), s.issues...)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"object": issues, "totalCount": len(issues)})
+}
+
+func (s *Server) handleIssueDetails(w http.ResponseWriter, r *http.Request, id string) {
+	issue, ok := s.issue(strings.TrimSuffix(id, "/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"companyShareId": issue.CompanyShareID,
+		"minUnit":        issue.MinUnit,
+		"maxUnit":        issue.MaxUnit,
+		"multipleOf":     issue.MultipleOf,
+		"sharePerUnit":   issue.SharePerUnit,
+	})
+}
+
+func (s *Server) handleRightEligibility(w http.ResponseWriter, r *http.Request, rest string) {
+	s.mu.Lock()
+	scenario := s.scenario
+	s.mu.Unlock()
+	if scenario == ScenarioRightUnavailable {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "Internal server error"})
+		return
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	issue, ok := s.issue(parts[0])
+	if !ok || len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"boid": parts[1], "eligibleKitta": issue.EligibleKitta})
+}
+
+func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
+	var application Application
+	if err := json.NewDecoder(r.Body).Decode(&application); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Invalid request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.scenario == ScenarioWrongPIN || (s.pin != "" && application.TransactionPIN != s.pin):
+		writeXMLError(w, http.StatusConflict, "You have entered wrong transaction PIN.")
+		return
+	case s.scenario == ScenarioApplicationInProcess:
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "Application in process. Please try again later."})
+		return
+	case s.scenario == ScenarioApplyUnavailable:
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "Service unavailable"})
+		return
+	}
+	for _, existing := range s.applications {
+		if existing.Demat == application.Demat && existing.CompanyShareID == application.CompanyShareID {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": "You have already applied for this issue."})
+			return
+		}
+	}
+
+	s.applications = append(s.applications, application)
+	writeJSON(w, http.StatusCreated, map[string]any{"status": "CREATED", "message": "Share has been applied successfully."})
+}
+
+func (s *Server) issue(id string) (Issue, bool) {
+	companyShareID, err := strconv.Atoi(id)
+	if err != nil {
+		return Issue{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, issue := range s.issues {
+		if int(issue.CompanyShareID) == companyShareID {
+			return issue, true
+		}
+	}
+	return Issue{}, false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeXMLError mirrors the XML error bodies MeroShare sends with 401 and some 409 responses.
+func writeXMLError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprint(w, xml.Header)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"response"`
+		Status  int      `xml:"statusCode"`
+		Message string   `xml:"message"`
+	}{Status: status, Message: message})
+}
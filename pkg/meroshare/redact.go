@@ -0,0 +1,74 @@
+package meroshare
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields are the JSON keys, compared case-insensitively, whose values never leave the
+// process: account passwords, the transaction PIN, the CRN and the session token.
+var sensitiveFields = map[string]bool{
+	"password":       true,
+	"newpassword":    true,
+	"oldpassword":    true,
+	"transactionpin": true,
+	"crnnumber":      true,
+	"authorization":  true,
+	"token":          true,
+}
+
+// sensitiveHeaders carry the MeroShare session token in both directions.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// RedactBody renders a request or response body for logging with every sensitive field masked.
+// Bodies that are not JSON are only described, since they cannot be masked reliably.
+func RedactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("[%d bytes, not JSON]", len(body))
+	}
+	data, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	return string(data)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// RedactHeaders returns a copy of header, flattened for logging, with the session token masked.
+func RedactHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for key, values := range header {
+		flat[key] = strings.Join(values, ", ")
+	}
+	for _, key := range sensitiveHeaders {
+		if _, ok := flat[key]; ok {
+			flat[key] = redacted
+		}
+	}
+	return flat
+}
@@ -0,0 +1,42 @@
+package meroshare
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	for _, tc := range []struct {
+		name, body, want string
+	}{
+		{"login", `{"clientId":101,"username":"alice","password":"hunter2"}`, `{"clientId":101,"password":"[REDACTED]","username":"alice"}`},
+		{"apply", `{"appliedKitta":"10","crnNumber":"CRN0001","transactionPIN":"1234","demat":"1301"}`, `{"appliedKitta":"10","crnNumber":"[REDACTED]","demat":"1301","transactionPIN":"[REDACTED]"}`},
+		{"nested", `{"object":[{"Token":"abc","name":"x"}]}`, `{"object":[{"Token":"[REDACTED]","name":"x"}]}`},
+		{"empty", ``, ``},
+		{"not json", `<error><message>wrong PIN 1234</message></error>`, `[48 bytes, not JSON]`},
+	} {
+		if got := RedactBody([]byte(tc.body)); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "secret-session-token")
+	header.Set("Content-Type", "application/json")
+
+	got := RedactHeaders(header)
+	if got["Authorization"] != "[REDACTED]" || got["Content-Type"] != "application/json" {
+		t.Fatalf("unexpected headers %v", got)
+	}
+	if header.Get("Authorization") != "secret-session-token" {
+		t.Fatal("RedactHeaders modified the request's headers")
+	}
+	for _, value := range got {
+		if strings.Contains(value, "secret") {
+			t.Fatalf("token leaked in %v", got)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	accountsDesc = prometheus.NewDesc(namespace+"_accounts", "MeroShare accounts by status.", []string{"status"}, nil)
+
+	redisHitsDesc     = prometheus.NewDesc(namespace+"_redis_pool_hits_total", "Times a free connection was found in the Redis pool.", nil, nil)
+	redisMissesDesc   = prometheus.NewDesc(namespace+"_redis_pool_misses_total", "Times a free connection was not found in the Redis pool.", nil, nil)
+	redisTimeoutsDesc = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "Times a wait for a Redis connection timed out.", nil, nil)
+	redisTotalDesc    = prometheus.NewDesc(namespace+"_redis_pool_connections", "Connections in the Redis pool.", nil, nil)
+	redisIdleDesc     = prometheus.NewDesc(namespace+"_redis_pool_idle_connections", "Idle connections in the Redis pool.", nil, nil)
+	redisStaleDesc    = prometheus.NewDesc(namespace+"_redis_pool_stale_connections_total", "Stale connections removed from the Redis pool.", nil, nil)
+)
+
+// collectTimeout bounds the database query run on each scrape.
+const collectTimeout = 5 * time.Second
+
+type accountStatusCollector struct {
+	count func(ctx context.Context) (map[string]int64, error)
+}
+
+func (c *accountStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- accountsDesc
+}
+
+func (c *accountStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	counts, err := c.count(ctx)
+	if err != nil {
+		logs.Warn("Failed to count accounts for metrics", map[string]any{"error": err})
+		return
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(accountsDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{redisHitsDesc, redisMissesDesc, redisTimeoutsDesc, redisTotalDesc, redisIdleDesc, redisStaleDesc} {
+		ch <- desc
+	}
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalDesc, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleDesc, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStaleDesc, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "meroshare"
+
+// Metrics records what the bot is doing for Prometheus. Each instance has its own registry, so
+// several can live in one process, as they do in the integration tests.
+type Metrics interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+	ObserveCDSCCall(endpoint, errorClass string, duration time.Duration)
+	RecordApplyOutcome(status string)
+	ObserveApplyRun(duration time.Duration)
+	RegisterDB(db *sql.DB)
+	RegisterRedis(client *redis.Client)
+	RegisterAccountStatuses(count func(ctx context.Context) (map[string]int64, error))
+	Handler() http.Handler
+}
+
+type metrics struct {
+	registry        *prometheus.Registry
+	httpDuration    *prometheus.HistogramVec
+	cdscCalls       *prometheus.CounterVec
+	cdscDuration    *prometheus.HistogramVec
+	applyOutcomes   *prometheus.CounterVec
+	applyRunSeconds prometheus.Histogram
+}
+
+func NewMetrics() Metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "API request latency by route and response status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		cdscCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cdsc_requests_total",
+			Help:      "Calls to the MeroShare (CDSC) API by endpoint and error class.",
+		}, []string{"endpoint", "error_class"}),
+		cdscDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "cdsc_request_duration_seconds",
+			Help:      "Latency of calls to the MeroShare (CDSC) API by endpoint.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"endpoint"}),
+		applyOutcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "apply_outcomes_total",
+			Help:      "Issues processed by apply runs, by the status recorded for them.",
+		}, []string{"status"}),
+		applyRunSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "apply_run_duration_seconds",
+			Help:      "Duration of apply runs.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.cdscCalls,
+		m.cdscDuration,
+		m.applyOutcomes,
+		m.applyRunSeconds,
+	)
+	return m
+}
+
+func (m *metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *metrics) ObserveCDSCCall(endpoint, errorClass string, duration time.Duration) {
+	m.cdscCalls.WithLabelValues(endpoint, errorClass).Inc()
+	m.cdscDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+func (m *metrics) RecordApplyOutcome(status string) {
+	m.applyOutcomes.WithLabelValues(status).Inc()
+}
+
+func (m *metrics) ObserveApplyRun(duration time.Duration) {
+	m.applyRunSeconds.Observe(duration.Seconds())
+}
+
+func (m *metrics) RegisterDB(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+func (m *metrics) RegisterRedis(client *redis.Client) {
+	m.registry.MustRegister(&redisPoolCollector{client: client})
+}
+
+// RegisterAccountStatuses exports the number of accounts in each status, counted at scrape time.
+func (m *metrics) RegisterAccountStatuses(count func(ctx context.Context) (map[string]int64, error)) {
+	m.registry.MustRegister(&accountStatusCollector{count: count})
+}
+
+func (m *metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
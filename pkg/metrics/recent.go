@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxRecentWindow is how far back RecentCDSCCalls can report.
+const MaxRecentWindow = 24 * time.Hour
+
+// CDSCCallStats counts the calls made to one MeroShare endpoint, by error class.
+type CDSCCallStats struct {
+	Endpoint  string           `json:"endpoint"`
+	Total     int64            `json:"total"`
+	Errors    int64            `json:"errors"`
+	ErrorRate float64          `json:"error_rate"`
+	ByClass   map[string]int64 `json:"by_error_class"`
+}
+
+// RecentCDSCCalls counts MeroShare calls per minute in Redis. Unlike the Prometheus counters, the
+// counts cover every replica and survive restarts, so the admin API can report recent error rates.
+type RecentCDSCCalls interface {
+	Record(ctx context.Context, endpoint, errorClass string, at time.Time)
+	Since(ctx context.Context, since time.Time) ([]CDSCCallStats, error)
+}
+
+type recentCDSCCalls struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRecentCDSCCalls(client *redis.Client, prefix string) RecentCDSCCalls {
+	return &recentCDSCCalls{client: client, prefix: prefix}
+}
+
+func (r *recentCDSCCalls) key(minute time.Time) string {
+	return r.prefix + ":" + strconv.FormatInt(minute.Unix(), 10)
+}
+
+func (r *recentCDSCCalls) Record(ctx context.Context, endpoint, errorClass string, at time.Time) {
+	key := r.key(at.Truncate(time.Minute))
+	pipe := r.client.Pipeline()
+	pipe.HIncrBy(ctx, key, endpoint+"|"+errorClass, 1)
+	pipe.Expire(ctx, key, MaxRecentWindow+time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logs.Warn("Failed to count MeroShare call", map[string]any{"error": err, "endpoint": endpoint})
+	}
+}
+
+// Since adds up the calls made from the minute containing since until now, busiest endpoint first.
+func (r *recentCDSCCalls) Since(ctx context.Context, since time.Time) ([]CDSCCallStats, error) {
+	if oldest := time.Now().Add(-MaxRecentWindow); since.Before(oldest) {
+		since = oldest
+	}
+	pipe := r.client.Pipeline()
+	var cmds []*redis.MapStringStringCmd
+	for minute := since.Truncate(time.Minute); !minute.After(time.Now()); minute = minute.Add(time.Minute) {
+		cmds = append(cmds, pipe.HGetAll(ctx, r.key(minute)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	byEndpoint := make(map[string]*CDSCCallStats)
+	for _, cmd := range cmds {
+		for field, value := range cmd.Val() {
+			endpoint, errorClass, ok := strings.Cut(field, "|")
+			count, err := strconv.ParseInt(value, 10, 64)
+			if !ok || err != nil {
+				continue
+			}
+			stats, ok := byEndpoint[endpoint]
+			if !ok {
+				stats = &CDSCCallStats{Endpoint: endpoint, ByClass: make(map[string]int64)}
+				byEndpoint[endpoint] = stats
+			}
+			stats.Total += count
+			stats.ByClass[errorClass] += count
+			if errorClass != "none" {
+				stats.Errors += count
+			}
+		}
+	}
+
+	result := make([]CDSCCallStats, 0, len(byEndpoint))
+	for _, stats := range byEndpoint {
+		stats.ErrorRate = float64(stats.Errors) / float64(stats.Total)
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Endpoint < result[j].Endpoint
+	})
+	return result, nil
+}
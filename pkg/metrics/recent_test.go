@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRecentCDSCCallsCountsErrorsInWindow(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	recent := NewRecentCDSCCalls(client, "cdsc")
+
+	now := time.Now()
+	recent.Record(ctx, "POST /auth/", "none", now)
+	recent.Record(ctx, "POST /auth/", "auth", now)
+	recent.Record(ctx, "POST /auth/", "none", now.Add(-5*time.Minute))
+	recent.Record(ctx, "GET /ownDetail/", "server", now.Add(-time.Minute))
+	recent.Record(ctx, "GET /ownDetail/", "server", now.Add(-2*time.Hour))
+
+	stats, err := recent.Since(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 endpoints, got %+v", stats)
+	}
+	auth, detail := stats[0], stats[1]
+	if auth.Endpoint != "POST /auth/" || auth.Total != 3 || auth.Errors != 1 || auth.ByClass["auth"] != 1 {
+		t.Errorf("unexpected stats for the login endpoint: %+v", auth)
+	}
+	if auth.ErrorRate < 0.33 || auth.ErrorRate > 0.34 {
+		t.Errorf("expected an error rate of 1/3, got %v", auth.ErrorRate)
+	}
+	if detail.Endpoint != "GET /ownDetail/" || detail.Total != 1 || detail.ErrorRate != 1 {
+		t.Errorf("expected the call from two hours ago to be left out, got %+v", detail)
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/meroshare"
+)
+
+// cdscTransport records every call to the MeroShare API by endpoint.
+type cdscTransport struct {
+	next     http.RoundTripper
+	metrics  Metrics
+	recent   RecentCDSCCalls
+	basePath string
+}
+
+// NewCDSCTransport wraps next to record calls made to the MeroShare API at baseURL. recent may be
+// nil when recent calls are not counted.
+func NewCDSCTransport(next http.RoundTripper, m Metrics, recent RecentCDSCCalls, baseURL string) http.RoundTripper {
+	return &cdscTransport{next: next, metrics: m, recent: recent, basePath: meroshare.BasePath(baseURL)}
+}
+
+func (t *cdscTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	endpoint, class := meroshare.Endpoint(req, t.basePath), errorClass(resp, err)
+	t.metrics.ObserveCDSCCall(endpoint, class, time.Since(start))
+	if t.recent != nil {
+		t.recent.Record(context.WithoutCancel(req.Context()), endpoint, class, start)
+	}
+	return resp, err
+}
+
+func errorClass(resp *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	switch {
+	case resp.StatusCode < 400:
+		return "none"
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "auth"
+	case resp.StatusCode == http.StatusConflict:
+		return "conflict"
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "rate_limited"
+	case resp.StatusCode < 500:
+		return "client"
+	}
+	return "server"
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	Metrics
+	endpoint   string
+	errorClass string
+}
+
+func (m *recordingMetrics) ObserveCDSCCall(endpoint, errorClass string, duration time.Duration) {
+	m.endpoint, m.errorClass = endpoint, errorClass
+}
+
+type stubTransport struct {
+	status int
+	err    error
+}
+
+func (t stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{StatusCode: t.status, Body: http.NoBody}, nil
+}
+
+func TestCDSCTransportLabels(t *testing.T) {
+	for _, tc := range []struct {
+		method, url string
+		transport   stubTransport
+		endpoint    string
+		errorClass  string
+	}{
+		{"POST", "https://cdsc.example/api/meroShare/auth/", stubTransport{status: 200}, "POST /auth/", "none"},
+		{"GET", "https://cdsc.example/api/meroShare/active/1234", stubTransport{status: 401}, "GET /active/:id", "auth"},
+		{"GET", "https://cdsc.example/api/meroShare/applicantForm/rightShare/eligibility/12/1301010000012345", stubTransport{status: 404}, "GET /applicantForm/rightShare/eligibility/:id/:id", "client"},
+		{"POST", "https://cdsc.example/api/meroShare/applicantForm/share/apply/", stubTransport{status: 409}, "POST /applicantForm/share/apply/", "conflict"},
+		{"GET", "https://cdsc.example/api/meroShare/bank/balance/7", stubTransport{status: 503}, "GET /bank/balance/:id", "server"},
+		{"GET", "https://cdsc.example/api/meroShare/ownDetail/", stubTransport{err: errors.New("connection refused")}, "GET /ownDetail/", "network"},
+	} {
+		m := &recordingMetrics{}
+		transport := NewCDSCTransport(tc.transport, m, nil, "https://cdsc.example/api/meroShare")
+		req, _ := http.NewRequest(tc.method, tc.url, nil)
+		transport.RoundTrip(req)
+		if m.endpoint != tc.endpoint || m.errorClass != tc.errorClass {
+			t.Errorf("%s %s: expected %q/%q, got %q/%q", tc.method, tc.url, tc.endpoint, tc.errorClass, m.endpoint, m.errorClass)
+		}
+	}
+}
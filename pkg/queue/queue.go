@@ -0,0 +1,246 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     string          `json:"user_id,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Status     string          `json:"status"`
+	Worker     string          `json:"worker,omitempty"`
+	Attempts   int             `json:"attempts"`
+	Error      string          `json:"error,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	// CorrelationID ties the worker's log lines to the request that enqueued the job.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Trace carries the trace context of the request that enqueued the job, so the worker's spans
+	// join the same trace.
+	Trace map[string]string `json:"trace,omitempty"`
+}
+
+// Queue is a Redis-backed job queue. Job IDs move from the pending list to the processing list
+// while a worker holds them, so a job whose worker died can be found and handed out again.
+type Queue interface {
+	Enqueue(ctx context.Context, job *Job) error
+	Dequeue(ctx context.Context, worker string, timeout time.Duration) (*Job, error)
+	Complete(ctx context.Context, job *Job, result any, jobErr error) error
+	Requeue(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id string) (*Job, error)
+	RequeueStale(ctx context.Context, olderThan time.Duration, maxAttempts int) (requeued int, failed int, err error)
+}
+
+type redisQueue struct {
+	client    *redis.Client
+	name      string
+	retention time.Duration
+}
+
+// NewQueue returns the queue stored under name. Job records are kept for retention after they
+// were last updated.
+func NewQueue(client *redis.Client, name string, retention time.Duration) Queue {
+	return &redisQueue{client: client, name: name, retention: retention}
+}
+
+func (q *redisQueue) pendingKey() string {
+	return q.name + ":pending"
+}
+
+func (q *redisQueue) processingKey() string {
+	return q.name + ":processing"
+}
+
+func (q *redisQueue) jobKey(id string) string {
+	return q.name + ":job:" + id
+}
+
+func (q *redisQueue) save(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return q.client.Set(ctx, q.jobKey(job.ID), data, q.retention).Err()
+}
+
+func (q *redisQueue) Enqueue(ctx context.Context, job *Job) error {
+	job.ID = uuid.NewString()
+	job.Status = StatusQueued
+	job.EnqueuedAt = time.Now()
+	if err := q.save(ctx, job); err != nil {
+		return err
+	}
+	return q.client.LPush(ctx, q.pendingKey(), job.ID).Err()
+}
+
+// Dequeue hands out the oldest pending job, waiting up to timeout for one to arrive. A timeout of
+// zero returns immediately. It returns nil when there is no job.
+func (q *redisQueue) Dequeue(ctx context.Context, worker string, timeout time.Duration) (*Job, error) {
+	var id string
+	var err error
+	if timeout > 0 {
+		id, err = q.client.BLMove(ctx, q.pendingKey(), q.processingKey(), "RIGHT", "LEFT", timeout).Result()
+	} else {
+		id, err = q.client.LMove(ctx, q.pendingKey(), q.processingKey(), "RIGHT", "LEFT").Result()
+	}
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := q.Get(ctx, id)
+	if errors.Is(err, ErrJobNotFound) {
+		// The record expired while the job waited; there is nothing left to run.
+		q.client.LRem(ctx, q.processingKey(), 1, id)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job.Status = StatusRunning
+	job.Worker = worker
+	job.Attempts++
+	job.StartedAt = &now
+	return job, q.save(ctx, job)
+}
+
+func (q *redisQueue) Complete(ctx context.Context, job *Job, result any, jobErr error) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = StatusSucceeded
+	if jobErr != nil {
+		job.Status = StatusFailed
+		job.Error = jobErr.Error()
+	}
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		job.Result = data
+	}
+	if err := q.save(ctx, job); err != nil {
+		return err
+	}
+	return q.client.LRem(ctx, q.processingKey(), 1, job.ID).Err()
+}
+
+// Requeue hands a job its worker gave up on back to the pending list, ahead of the jobs waiting there.
+func (q *redisQueue) Requeue(ctx context.Context, job *Job) error {
+	_, err := q.requeue(ctx, job)
+	return err
+}
+
+// requeue moves the job from the processing list back to pending. Only the caller whose LREM removed
+// the ID requeues it, so a job is never put back twice.
+func (q *redisQueue) requeue(ctx context.Context, job *Job) (bool, error) {
+	removed, err := q.client.LRem(ctx, q.processingKey(), 1, job.ID).Result()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+	job.Status = StatusQueued
+	job.Worker = ""
+	job.StartedAt = nil
+	if err := q.save(ctx, job); err != nil {
+		return false, err
+	}
+	return true, q.client.RPush(ctx, q.pendingKey(), job.ID).Err()
+}
+
+func (q *redisQueue) Get(ctx context.Context, id string) (*Job, error) {
+	data, err := q.client.Get(ctx, q.jobKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// RequeueStale puts jobs that have been processing for longer than olderThan back on the pending
+// list, on the assumption that their worker died. A job without a start time was only just handed
+// out and its worker has yet to record it, so it is left alone. A job that has already been handed
+// out maxAttempts times is failed instead, so one that keeps killing its worker does not run forever.
+func (q *redisQueue) RequeueStale(ctx context.Context, olderThan time.Duration, maxAttempts int) (int, int, error) {
+	ids, err := q.client.LRange(ctx, q.processingKey(), 0, -1).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	requeued, failed := 0, 0
+	for _, id := range ids {
+		job, err := q.Get(ctx, id)
+		if errors.Is(err, ErrJobNotFound) {
+			q.client.LRem(ctx, q.processingKey(), 1, id)
+			continue
+		}
+		if err != nil {
+			return requeued, failed, err
+		}
+		if job.StartedAt == nil || time.Since(*job.StartedAt) < olderThan {
+			continue
+		}
+
+		if job.Attempts >= maxAttempts {
+			ok, err := q.abandon(ctx, job)
+			if err != nil {
+				return requeued, failed, err
+			}
+			if ok {
+				failed++
+			}
+			continue
+		}
+		ok, err := q.requeue(ctx, job)
+		if err != nil {
+			return requeued, failed, err
+		}
+		if ok {
+			requeued++
+		}
+	}
+	return requeued, failed, nil
+}
+
+// abandon takes a stale job off the processing list and records it as failed. Like requeue, only
+// the caller whose LREM removed the ID records it.
+func (q *redisQueue) abandon(ctx context.Context, job *Job) (bool, error) {
+	removed, err := q.client.LRem(ctx, q.processingKey(), 1, job.ID).Result()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+	now := time.Now()
+	job.Status = StatusFailed
+	job.Error = fmt.Sprintf("worker was lost on each of %d attempts", job.Attempts)
+	job.FinishedAt = &now
+	return true, q.save(ctx, job)
+}
@@ -0,0 +1,170 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestQueue(t *testing.T) Queue {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewQueue(client, "jobs", time.Hour)
+}
+
+func TestQueueRunsJobsInOrder(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+
+	first := &Job{Type: "apply"}
+	second := &Job{Type: "sync"}
+	if err := q.Enqueue(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := q.Dequeue(ctx, "worker-1", 0)
+	if err != nil || job == nil || job.ID != first.ID {
+		t.Fatalf("expected the first job, got %+v (%v)", job, err)
+	}
+	if job.Status != StatusRunning || job.Worker != "worker-1" || job.Attempts != 1 {
+		t.Fatalf("expected a running job held by worker-1, got %+v", job)
+	}
+	if err := q.Complete(ctx, job, map[string]int{"applied": 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	job, _ = q.Dequeue(ctx, "worker-1", 0)
+	if err := q.Complete(ctx, job, nil, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+
+	if job, _ := q.Dequeue(ctx, "worker-1", 0); job != nil {
+		t.Fatalf("expected an empty queue, got %+v", job)
+	}
+
+	done, err := q.Get(ctx, first.ID)
+	if err != nil || done.Status != StatusSucceeded || string(done.Result) != `{"applied":1}` {
+		t.Fatalf("expected a succeeded job with its result, got %+v (%v)", done, err)
+	}
+	failed, _ := q.Get(ctx, second.ID)
+	if failed.Status != StatusFailed || failed.Error != "boom" {
+		t.Fatalf("expected a failed job with its error, got %+v", failed)
+	}
+	if _, err := q.Get(ctx, "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestRequeueStaleJobs(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+
+	if err := q.Enqueue(ctx, &Job{Type: "apply"}); err != nil {
+		t.Fatal(err)
+	}
+	job, _ := q.Dequeue(ctx, "worker-1", 0)
+
+	if n, _, err := q.RequeueStale(ctx, time.Minute, 3); err != nil || n != 0 {
+		t.Fatalf("expected a fresh job to stay with its worker, got %d (%v)", n, err)
+	}
+	if n, _, err := q.RequeueStale(ctx, 0, 3); err != nil || n != 1 {
+		t.Fatalf("expected the stale job to be requeued, got %d (%v)", n, err)
+	}
+	if requeued, _ := q.Get(ctx, job.ID); requeued.StartedAt != nil || requeued.Worker != "" {
+		t.Fatalf("expected the requeued job to forget its last start, got %+v", requeued)
+	}
+
+	// A worker has moved the job to processing but not yet recorded that it started it.
+	rq := q.(*redisQueue)
+	if err := rq.client.LMove(ctx, rq.pendingKey(), rq.processingKey(), "RIGHT", "LEFT").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _, err := q.RequeueStale(ctx, 0, 3); err != nil || n != 0 {
+		t.Fatalf("expected a job that is being handed out to be left alone, got %d (%v)", n, err)
+	}
+	if err := rq.client.LMove(ctx, rq.processingKey(), rq.pendingKey(), "LEFT", "RIGHT").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	retried, err := q.Dequeue(ctx, "worker-2", 0)
+	if err != nil || retried == nil || retried.ID != job.ID {
+		t.Fatalf("expected the stale job to be handed out again, got %+v (%v)", retried, err)
+	}
+	if retried.Attempts != 2 || retried.Worker != "worker-2" {
+		t.Fatalf("expected a second attempt on worker-2, got %+v", retried)
+	}
+}
+
+func TestRequeueStaleFailsJobOutOfAttempts(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+
+	if err := q.Enqueue(ctx, &Job{Type: "apply"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(ctx, &Job{Type: "sync"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The apply job kills its worker every time it runs.
+	poison, _ := q.Dequeue(ctx, "worker-1", 0)
+	if requeued, failed, err := q.RequeueStale(ctx, 0, 2); err != nil || requeued != 1 || failed != 0 {
+		t.Fatalf("expected the job to be requeued after its first attempt, got %d requeued and %d failed (%v)", requeued, failed, err)
+	}
+	if job, _ := q.Dequeue(ctx, "worker-1", 0); job == nil || job.ID != poison.ID {
+		t.Fatalf("expected the requeued job to run next, got %+v", job)
+	}
+	if requeued, failed, err := q.RequeueStale(ctx, 0, 2); err != nil || requeued != 0 || failed != 1 {
+		t.Fatalf("expected the job to be failed after 2 attempts, got %d requeued and %d failed (%v)", requeued, failed, err)
+	}
+
+	job, _ := q.Get(ctx, poison.ID)
+	if job.Status != StatusFailed || job.Error == "" || job.FinishedAt == nil {
+		t.Fatalf("expected the job to be recorded as failed, got %+v", job)
+	}
+	next, _ := q.Dequeue(ctx, "worker-2", 0)
+	if next == nil || next.Type != "sync" {
+		t.Fatalf("expected the job behind it to run, got %+v", next)
+	}
+	if next, _ := q.Dequeue(ctx, "worker-2", 0); next != nil {
+		t.Fatalf("expected the failed job to stay off the queue, got %+v", next)
+	}
+}
+
+func TestRequeuePutsJobFirst(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+
+	first := &Job{Type: "apply"}
+	second := &Job{Type: "sync"}
+	q.Enqueue(ctx, first)
+	q.Enqueue(ctx, second)
+
+	job, _ := q.Dequeue(ctx, "worker-1", 0)
+	if err := q.Requeue(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Requeue(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	next, _ := q.Dequeue(ctx, "worker-2", 0)
+	if next == nil || next.ID != first.ID {
+		t.Fatalf("expected the requeued job to run next, got %+v", next)
+	}
+	next, _ = q.Dequeue(ctx, "worker-2", 0)
+	if next == nil || next.ID != second.ID {
+		t.Fatalf("expected the other job after it, got %+v", next)
+	}
+	if extra, _ := q.Dequeue(ctx, "worker-2", 0); extra != nil {
+		t.Fatalf("expected a job requeued twice to be queued once, got %+v", extra)
+	}
+}
@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/redis/go-redis/v9"
+)
+
+type LeaderStatus struct {
+	Identity string     `json:"identity"`
+	IsLeader bool       `json:"is_leader"`
+	Leader   string     `json:"leader"`
+	Since    *time.Time `json:"since,omitempty"`
+}
+
+// LeaderElector holds a lease on a Redis key so that exactly one process at a time is the leader.
+// The leader renews the lease every third of its TTL and steps down once it cannot renew it.
+type LeaderElector interface {
+	Start()
+	Stop()
+	IsLeader() bool
+	LocalStatus() LeaderStatus
+	Status() LeaderStatus
+}
+
+type leaderElector struct {
+	client   *redis.Client
+	key      string
+	identity string
+	ttl      time.Duration
+
+	mu          sync.RWMutex
+	leader      bool
+	since       time.Time
+	lastRenewed time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewLeaderElector(client *redis.Client, key, identity string, ttl time.Duration) LeaderElector {
+	return &leaderElector{
+		client:   client,
+		key:      key,
+		identity: identity,
+		ttl:      ttl,
+	}
+}
+
+func (e *leaderElector) Start() {
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			e.tick()
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the renewal loop and hands the lease back so another process can take over at once.
+func (e *leaderElector) Stop() {
+	if e.stop == nil {
+		return
+	}
+	close(e.stop)
+	<-e.done
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leader {
+		if err := releaseScript.Run(context.Background(), e.client, []string{e.key}, e.identity).Err(); err != nil {
+			logs.Warn("Failed to release leader lease", map[string]any{"error": err})
+		}
+		e.leader = false
+	}
+}
+
+func (e *leaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// LocalStatus reports whether this process is the leader without asking Redis, so it never blocks on
+// an outage. It leaves Leader empty.
+func (e *leaderElector) LocalStatus() LeaderStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	status := LeaderStatus{Identity: e.identity, IsLeader: e.leader}
+	if e.leader {
+		since := e.since
+		status.Since = &since
+	}
+	return status
+}
+
+// Status adds the identity of the current leader, whichever process it is, read from Redis.
+func (e *leaderElector) Status() LeaderStatus {
+	status := e.LocalStatus()
+	leader, err := e.client.Get(context.Background(), e.key).Result()
+	if err != nil && err != redis.Nil {
+		logs.Warn("Failed to read leader lease", map[string]any{"error": err})
+	}
+	status.Leader = leader
+	return status
+}
+
+func (e *leaderElector) tick() {
+	ctx := context.Background()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.leader {
+		renewed, err := extendScript.Run(ctx, e.client, []string{e.key}, e.identity, e.ttl.Milliseconds()).Int()
+		switch {
+		case err == nil && renewed == 1:
+			e.lastRenewed = time.Now()
+		case err == nil:
+			e.stepDown("lease taken over")
+		case time.Since(e.lastRenewed) >= e.ttl:
+			// Another process may have acquired the lease by now, so stop acting as leader.
+			e.stepDown(err.Error())
+		default:
+			logs.Warn("Failed to renew leader lease", map[string]any{"error": err})
+		}
+		return
+	}
+
+	acquired, err := e.client.SetNX(ctx, e.key, e.identity, e.ttl).Result()
+	if err != nil {
+		logs.Warn("Failed to acquire leader lease", map[string]any{"error": err})
+		return
+	}
+	if acquired {
+		e.leader = true
+		e.since = time.Now()
+		e.lastRenewed = e.since
+		logs.Info("Became leader", map[string]any{"identity": e.identity})
+	}
+}
+
+func (e *leaderElector) stepDown(reason string) {
+	e.leader = false
+	logs.Warn("Lost leadership", map[string]any{"identity": e.identity, "reason": reason})
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMain(m *testing.M) {
+	logs.InitLogger()
+	logs.Logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestLeaderElectionFailover(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ttl := 15 * time.Second
+	first := NewLeaderElector(client, "leader", "replica-1", ttl).(*leaderElector)
+	second := NewLeaderElector(client, "leader", "replica-2", ttl).(*leaderElector)
+
+	first.tick()
+	second.tick()
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("expected replica-1 to lead, got first=%v second=%v", first.IsLeader(), second.IsLeader())
+	}
+	if status := second.Status(); status.Leader != "replica-1" || status.IsLeader {
+		t.Fatalf("expected followers to report replica-1 as leader, got %+v", status)
+	}
+
+	// Renewals keep the lease alive past its original TTL.
+	mr.FastForward(ttl / 2)
+	first.tick()
+	mr.FastForward(ttl / 2)
+	second.tick()
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatal("expected replica-1 to keep leadership while renewing")
+	}
+
+	// replica-1 stalls long enough for the lease to expire and replica-2 takes over.
+	mr.FastForward(ttl)
+	second.tick()
+	if !second.IsLeader() {
+		t.Fatal("expected replica-2 to take over an expired lease")
+	}
+	first.tick()
+	if first.IsLeader() {
+		t.Fatal("expected replica-1 to step down once its lease was taken")
+	}
+
+	second.Start()
+	second.Stop()
+	if second.IsLeader() || mr.Exists("leader") {
+		t.Fatal("expected Stop to hand back the lease")
+	}
+}
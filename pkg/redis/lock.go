@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/asrma7/meroshare-bot/pkg/logs"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrLockHeld = errors.New("lock is held by another process")
+
+// Only the holder's token may release or extend a lock, so a process whose lease expired cannot
+// drop a lock another process has since taken.
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// Lock is a lease on a Redis key, held until it is released or its TTL runs out.
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+// AcquireLock takes the lock on key for ttl, returning ErrLockHeld if another holder has it.
+func AcquireLock(ctx context.Context, client *redis.Client, key string, ttl time.Duration) (*Lock, error) {
+	token := uuid.NewString()
+	ok, err := client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockHeld
+	}
+	return &Lock{client: client, key: key, token: token}, nil
+}
+
+func (l *Lock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+}
+
+// Extend resets the lock's TTL, returning ErrLockHeld if the lease was already lost.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := extendScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrLockHeld
+	}
+	return nil
+}
+
+// Hold extends the lock every ttl/3 until the returned cancel func is called. The returned context
+// is cancelled as soon as the lease is lost, so the holder stops before another process takes over.
+func (l *Lock) Hold(ctx context.Context, ttl time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := l.Extend(ctx, ttl)
+			if errors.Is(err, ErrLockHeld) {
+				logs.Warn("Lost lock", map[string]any{"key": l.key})
+				cancel()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				logs.Warn("Failed to extend lock", map[string]any{"error": err, "key": l.key})
+			}
+		}
+	}()
+	return ctx, cancel
+}
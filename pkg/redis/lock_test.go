@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLockHoldRenewsUntilLost(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ttl := 150 * time.Millisecond
+	lock, err := AcquireLock(context.Background(), client, "lock", ttl)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	ctx, stop := lock.Hold(context.Background(), ttl)
+	defer stop()
+
+	mr.SetTTL("lock", time.Millisecond)
+	time.Sleep(ttl / 2)
+	if got := mr.TTL("lock"); got != ttl {
+		t.Fatalf("expected the lock to be renewed to %v, got %v", ttl, got)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the context to stay live while the lock is held")
+	}
+
+	// Another process takes the lock over after the lease ran out.
+	mr.Set("lock", "someone-else")
+	select {
+	case <-ctx.Done():
+	case <-time.After(ttl):
+		t.Fatal("expected the context to be cancelled once the lock was lost")
+	}
+	if got, _ := mr.Get("lock"); got != "someone-else" {
+		t.Fatalf("expected the new holder to keep the lock, got %q", got)
+	}
+}
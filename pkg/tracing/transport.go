@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/asrma7/meroshare-bot/pkg/meroshare"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cdscTransport starts a client span for every call to the MeroShare API. Only the method, the
+// endpoint with IDs masked, the status and the account ID are recorded: never the URL, headers or
+// body, which carry DMAT numbers, passwords, PINs and the session token. Trace headers are not sent
+// to MeroShare.
+type cdscTransport struct {
+	next     http.RoundTripper
+	basePath string
+}
+
+// NewCDSCTransport wraps next to trace calls made to the MeroShare API at baseURL.
+func NewCDSCTransport(next http.RoundTripper, baseURL string) http.RoundTripper {
+	return &cdscTransport{next: next, basePath: meroshare.BasePath(baseURL)}
+}
+
+func (t *cdscTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := meroshare.Endpoint(req, t.basePath)
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("cdsc.endpoint", endpoint),
+	}
+	if accountID := AccountID(req.Context()); accountID != "" {
+		attributes = append(attributes, attribute.String("account.id", accountID))
+	}
+	ctx, span := Tracer().Start(req.Context(), "CDSC "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	defer span.End()
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		RecordError(span, err)
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		RecordError(span, fmt.Errorf("MeroShare responded %d", resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -30,3 +30,33 @@ func ConvertBSToAD(bsYear, bsMonth, bsDay int) (time.Time, error) {
 
 	return baseAD.AddDate(0, 0, days), nil
 }
+
+var issueDateLayouts = []string{
+	"Jan 2, 2006 3:04:05 PM",
+	"Jan 2, 2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC3339,
+}
+
+// NepalLocation returns Nepal time, falling back to a fixed UTC+5:45 zone when the system has no
+// time zone database.
+func NepalLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kathmandu")
+	if err != nil {
+		return time.FixedZone("NPT", 5*60*60+45*60)
+	}
+	return loc
+}
+
+// ParseIssueDate parses the issue open/close dates MeroShare returns, e.g. "Sep 18, 2025 5:00:00 PM",
+// which are in Nepal time.
+func ParseIssueDate(value string) (time.Time, error) {
+	loc := NepalLocation()
+	for _, layout := range issueDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognised issue date: " + value)
+}